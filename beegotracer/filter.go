@@ -0,0 +1,71 @@
+package beegotracer
+
+import (
+	"strings"
+
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"github.com/opentracing/opentracing-go"
+	"github.com/xiaoyang-chen/tracer"
+)
+
+// routerPatternDataKey beego匹配到路由后将路由规则存入 context.BeegoInput 的data中使用的key
+const routerPatternDataKey = "RouterPattern"
+
+// spanDataKey Filter 将span存入 context.BeegoInput 的data中使用的key, 供 Controller 使用
+const spanDataKey = "ot-mw-span"
+
+const splatParamKey = ":splat"
+const emptyString = ""
+
+const tagKeyHttpRoute = "http.route"
+const tagKeyRouteParamPrefix = "http.route.param."
+
+// InsertFilter 在beego所有路由的 beego.BeforeExec 阶段插入 Filter(t), span仍由 t.HttpMiddleWare 生成及结束, 所以需同时通过 beego.RunWithMiddleWares 使用; example: beegotracer.InsertFilter(t); beego.RunWithMiddleWares(addr, t.HttpMiddleWare)
+func InsertFilter(t tracer.Tracer) {
+	beego.InsertFilter("*", beego.BeforeExec, Filter(t), false, true)
+}
+
+// Filter 返回beego的过滤器, 将 t.HttpMiddleWare 生成的span以 请求方法+匹配到的路由规则 命名(如 GET /users/:id), 避免每个id都成为jaeger中单独的操作, 并将路由参数记录为tag(如 http.route.param.id=123); 需在 beego.BeforeExec 阶段执行, 此时beego已完成路由匹配; 同一路由下的请求需按控制器方法区分时, 控制器可嵌入 Controller
+func Filter(t tracer.Tracer) beego.FilterFunc {
+
+	return func(ctx *context.Context) {
+
+		var span = t.SpanFromContext(ctx.Request.Context())
+		if span == nil {
+			return
+		}
+		ctx.Input.SetData(spanDataKey, span)
+
+		var pattern, _ = ctx.Input.GetData(routerPatternDataKey).(string)
+		if pattern != emptyString {
+			span.SetOperationName(ctx.Request.Method + " " + pattern)
+			span.SetTag(tagKeyHttpRoute, pattern)
+		}
+		for key, val := range ctx.Input.Params() {
+			// 以"*"注册的过滤器匹配时也会设置:splat, 只有路由规则本身含有通配符时才是路由参数
+			if key == splatParamKey && !strings.Contains(pattern, "*") {
+				continue
+			}
+			span.SetTag(tagKeyRouteParamPrefix+strings.TrimPrefix(key, ":"), val)
+		}
+	}
+}
+
+// Controller 嵌入后将 Filter 所在请求的span以 控制器名.方法名 命名(如 UserController.Get); 控制器自定义Prepare时需调用 Controller.Prepare
+type Controller struct{ beego.Controller }
+
+// Prepare 在执行控制器方法前修改span的操作名称
+func (c *Controller) Prepare() {
+
+	var span, ok = c.Ctx.Input.GetData(spanDataKey).(opentracing.Span)
+	if !ok {
+		return
+	}
+	var controllerName, actionName = c.GetControllerAndAction()
+	if beego.HTTPMETHOD[actionName] {
+		// 未指定映射方法的路由, beego以请求方法(如GET)作为方法名并调用对应的Get等方法
+		actionName = actionName[:1] + strings.ToLower(actionName[1:])
+	}
+	span.SetOperationName(controllerName + "." + actionName)
+}
@@ -0,0 +1,95 @@
+package beegotracer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/astaxie/beego"
+	"github.com/uber/jaeger-client-go"
+	"github.com/xiaoyang-chen/tracer"
+)
+
+type userController struct{ Controller }
+
+func (c *userController) Get() { c.Ctx.WriteString("get") }
+
+func (c *userController) Detail() { c.Ctx.WriteString("detail") }
+
+type plainController struct{ beego.Controller }
+
+func (c *plainController) Get() { c.Ctx.WriteString("plain") }
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		wantOpName string
+		wantTags   map[string]interface{}
+	}{
+		{
+			name:       "router pattern",
+			url:        "/plain/123",
+			wantOpName: "GET /plain/:id",
+			wantTags:   map[string]interface{}{"http.route": "/plain/:id", "http.route.param.id": "123"},
+		},
+		{
+			name:       "controller rest method",
+			url:        "/users/123",
+			wantOpName: "userController.Get",
+			wantTags:   map[string]interface{}{"http.route": "/users/:id", "http.route.param.id": "123"},
+		},
+		{
+			name:       "controller mapping method",
+			url:        "/users/123/detail",
+			wantOpName: "userController.Detail",
+			wantTags:   map[string]interface{}{"http.route.param.id": "123"},
+		},
+		{
+			name:       "not found",
+			url:        "/unknown",
+			wantOpName: "HTTP GET /unknown",
+		},
+	}
+	var reporter = jaeger.NewInMemoryReporter()
+	var ti, err = tracer.NewTracer(
+		"beegotracer-test", tracer.WithReporter(reporter),
+		tracer.WithLogger(tracer.NewNopLogger()),
+	)
+	if err != nil {
+		t.Fatalf("NewTracer() error = %v", err)
+	}
+	defer ti.Close()
+	var handlers = beego.NewControllerRegister()
+	handlers.Add("/plain/:id", &plainController{})
+	handlers.Add("/users/:id", &userController{})
+	handlers.Add("/users/:id/detail", &userController{}, "get:Detail")
+	handlers.InsertFilter("*", beego.BeforeExec, Filter(ti), false, true)
+	var handler = ti.HttpMiddleWare(handlers)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reporter.Reset()
+			handler.ServeHTTP(
+				httptest.NewRecorder(),
+				httptest.NewRequest(http.MethodGet, tt.url, nil),
+			)
+			if got := reporter.SpansSubmitted(); got != 1 {
+				t.Fatalf("SpansSubmitted() = %v, want 1", got)
+			}
+			var span = reporter.GetSpans()[0].(*jaeger.Span)
+			if span.OperationName() != tt.wantOpName {
+				t.Errorf("OperationName() = %v, want %v", span.OperationName(), tt.wantOpName)
+			}
+			var tags = span.Tags()
+			for key, want := range tt.wantTags {
+				if tags[key] != want {
+					t.Errorf("tag %v = %v, want %v", key, tags[key], want)
+				}
+			}
+			if _, ok := tags["http.route.param.splat"]; ok {
+				t.Errorf("filter splat param tagged: %v", tags)
+			}
+		})
+	}
+}
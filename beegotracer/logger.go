@@ -0,0 +1,25 @@
+// Package beegotracer tracer与beego的集成, 单独成包, 不使用beego的服务无需引入beego
+package beegotracer
+
+import (
+	"github.com/astaxie/beego/logs"
+	"github.com/xiaoyang-chen/tracer"
+)
+
+// NewLogger 通过beego的 *logs.BeeLogger 输出tracer内部信息, Error输出为Error级别, Infof输出为Info级别, l为nil时使用beego默认的BeeLogger; example: tracer.NewTracer("srv", tracer.WithLogger(beegotracer.NewLogger(nil)))
+func NewLogger(l *logs.BeeLogger) tracer.Logger {
+
+	if l == nil {
+		l = logs.GetBeeLogger()
+	}
+	return &beegoLogger{l: l}
+}
+
+type beegoLogger struct{ l *logs.BeeLogger }
+
+// Error msg中可能包含%, 所以不能作为format直接输出
+func (bl *beegoLogger) Error(msg string) { bl.l.Error("%s", msg) }
+
+func (bl *beegoLogger) Infof(msg string, args ...interface{}) {
+	bl.l.Info(msg, args...)
+}
@@ -0,0 +1,365 @@
+package tracer
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/opentracing/opentracing-go"
+	opentracingLog "github.com/opentracing/opentracing-go/log"
+	"github.com/uber/jaeger-client-go"
+	"github.com/valyala/fasthttp"
+)
+
+// defaultCaptureMaxBodyBytes 记录body时默认的最大字节数
+const defaultCaptureMaxBodyBytes = 4 << 10
+
+// RedactedValue RedactKeys 替换敏感字段的值时使用的内容
+const RedactedValue = "[REDACTED]"
+
+// unparseableJsonBody 开启脱敏后, json格式的body无法解析(通常是超出最大字节数被截断)时记录的内容, 避免敏感字段未经脱敏被记录
+const unparseableJsonBody = "[unparseable json body omitted]"
+
+// unredactableBody 开启脱敏后, 无法脱敏的body(json及form以外的格式, 如xml及text)记录的内容, 避免其中的敏感内容未经脱敏被记录
+const unredactableBody = "[unredactable body omitted]"
+
+const (
+	logEventHttpRequest     = "http.request"
+	logEventHttpResponse    = "http.response"
+	logKeyHttpHeaderPrefix  = "http.header."
+	logKeyHttpBody          = "http.body"
+	logKeyHttpBodyTruncated = "http.body.truncated"
+)
+
+// defaultCaptureContentTypes 默认记录body的Content-Type
+var defaultCaptureContentTypes = []string{
+	"application/json",
+	"application/x-www-form-urlencoded",
+	"application/xml",
+	"text/",
+}
+
+// defaultRedactKeys 默认脱敏的http头及body字段名称
+var defaultRedactKeys = []string{
+	"authorization", "proxy-authorization", "cookie", "set-cookie",
+	"password", "passwd", "secret", "token", "access_token", "refresh_token",
+	"api_key", "apikey",
+}
+
+// captureJsonSerializer 脱敏时解析json格式的body, 使用json.Number保留数字的原始精度
+var captureJsonSerializer serializer = jsoniter.Config{
+	EscapeHTML:             true,
+	SortMapKeys:            true,
+	ValidateJsonRawMessage: true,
+	UseNumber:              true,
+}.Froze()
+
+// Redactor 记录http头及body前的脱敏函数, key为http头的名称或body中的字段名称(json中嵌套的字段同样适用, form中为参数名称), value为对应的值, 返回值为实际记录的内容; json中字段的值为对象或数组时value为空字符串, 返回非空值时整个对象或数组替换为该值; 通过 WithCaptureRedactor 设置
+type Redactor func(key, value string) (redacted string)
+
+// RedactKeys 返回将名称为keys中任一值(不区分大小写)的http头及body字段替换为 RedactedValue 的 Redactor, example: RedactKeys("password", "Authorization")
+func RedactKeys(keys ...string) Redactor {
+
+	var set = make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		set[strings.ToLower(key)] = struct{}{}
+	}
+	return func(key, value string) (redacted string) {
+
+		if _, ok := set[strings.ToLower(key)]; ok {
+			redacted = RedactedValue
+		} else {
+			redacted = value
+		}
+		return
+	}
+}
+
+// WithCaptureHeaders 设置是否将 HttpMiddleWare 及 fasthttp 客户端(GetFasthttp等方法)请求和响应的http头记录到span的log中, 默认不记录; 仅记录被采样的span, 记录前通过 WithCaptureRedactor 设置的 Redactor 脱敏
+func WithCaptureHeaders(capture bool) Option {
+	return func(opts *options) { opts.capture.headers = capture }
+}
+
+// WithCaptureBodies 设置是否将 HttpMiddleWare 及 fasthttp 客户端请求和响应的body记录到span的log中, 默认不记录; 每个body最多记录maxBodyBytes个字节(<=0时为4KB), HttpMiddleWare 中只记录handler实际读取及写入的部分; 仅记录被采样的span, 及Content-Type在 WithCaptureContentTypes 设置范围内且未经压缩的body; json及form格式的body记录前通过 WithCaptureRedactor 设置的 Redactor 脱敏, json被截断而无法解析时不记录内容; 设置了 Redactor 时无法脱敏的其他格式(如xml及text)同样不记录内容, 需要记录时可通过 WithCaptureRedactor(nil) 关闭脱敏
+func WithCaptureBodies(capture bool, maxBodyBytes int) Option {
+	return func(opts *options) {
+		opts.capture.bodies = capture
+		if maxBodyBytes <= 0 {
+			maxBodyBytes = defaultCaptureMaxBodyBytes
+		}
+		opts.capture.maxBodyBytes = maxBodyBytes
+	}
+}
+
+// WithCaptureContentTypes 设置记录body的Content-Type, 以"/"结尾的值按前缀匹配, 其余值需与Content-Type(不含charset等参数)完全相同; 默认为 application/json, application/x-www-form-urlencoded, application/xml 及 text/, example: WithCaptureContentTypes("application/json", "application/grpc-web-text")
+func WithCaptureContentTypes(contentTypes ...string) Option {
+	return func(opts *options) {
+
+		opts.capture.contentTypes = make([]string, len(contentTypes))
+		for i, contentType := range contentTypes {
+			opts.capture.contentTypes[i] = strings.ToLower(contentType)
+		}
+	}
+}
+
+// WithCaptureRedactor 设置记录http头及body前的脱敏函数, 默认为 RedactKeys 对Authorization, Cookie, password, token等常见敏感字段脱敏; 为nil时不脱敏
+func WithCaptureRedactor(redactor Redactor) Option {
+	return func(opts *options) { opts.capture.redactor = redactor }
+}
+
+// captureOptions 记录http头及body的配置
+type captureOptions struct {
+	headers      bool
+	bodies       bool
+	maxBodyBytes int
+	contentTypes []string
+	redactor     Redactor
+}
+
+func newDefaultCaptureOptions() captureOptions {
+	return captureOptions{
+		maxBodyBytes: defaultCaptureMaxBodyBytes,
+		contentTypes: defaultCaptureContentTypes,
+		redactor:     RedactKeys(defaultRedactKeys...),
+	}
+}
+
+// shouldCapture 判断是否需要为span记录http头或body, 只记录被采样的span
+func (co *captureOptions) shouldCapture(span opentracing.Span) bool {
+
+	if !co.headers && !co.bodies {
+		return false
+	}
+	var spanCtx, ok = span.Context().(jaeger.SpanContext)
+	return ok && spanCtx.IsSampled()
+}
+
+// newBodyBuffer 开启了body记录时返回用于记录body的 captureBuffer, 否则返回nil
+func (co *captureOptions) newBodyBuffer() *captureBuffer {
+
+	if !co.bodies {
+		return nil
+	}
+	return &captureBuffer{max: co.maxBodyBytes}
+}
+
+// logHttpCapture 将http头及body以一条log的形式记录到span中, event为 logEventHttpRequest 或 logEventHttpResponse
+func (co *captureOptions) logHttpCapture(
+	span opentracing.Span, event string, header http.Header, body *captureBuffer,
+) {
+
+	var fields = []opentracingLog.Field{opentracingLog.String("event", event)}
+	if co.headers {
+		var keys = make([]string, 0, len(header))
+		for key := range header {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			var values = make([]string, len(header[key]))
+			for i, value := range header[key] {
+				values[i] = co.redact(key, value)
+			}
+			fields = append(fields, opentracingLog.String(
+				logKeyHttpHeaderPrefix+key, strings.Join(values, ", "),
+			))
+		}
+	}
+	if body != nil && len(body.buf) != 0 {
+		if encoding := header.Get("Content-Encoding"); encoding != emptyString &&
+			encoding != "identity" {
+			// 压缩后的body无法阅读
+			body = nil
+		}
+	}
+	if body != nil && len(body.buf) != 0 {
+		var contentType = header.Get("Content-Type")
+		if contentType == emptyString {
+			contentType = http.DetectContentType(body.buf)
+		}
+		if mediaType := getMediaType(contentType); co.allowMediaType(mediaType) {
+			fields = append(fields, opentracingLog.String(
+				logKeyHttpBody, co.redactBody(mediaType, body.buf),
+			))
+			if body.truncated {
+				fields = append(fields, opentracingLog.Bool(logKeyHttpBodyTruncated, true))
+			}
+		}
+	}
+	span.LogFields(fields...)
+}
+
+// logHttpExchange 记录 HttpMiddleWare 中请求和响应的http头及body
+func (co *captureOptions) logHttpExchange(
+	span opentracing.Span, reqHeader http.Header, reqBody *captureBuffer,
+	respHeader http.Header, respBody *captureBuffer,
+) {
+
+	co.logHttpCapture(span, logEventHttpRequest, reqHeader, reqBody)
+	co.logHttpCapture(span, logEventHttpResponse, respHeader, respBody)
+}
+
+// logFasthttpRequestCapture 记录 fasthttp.Request 的http头及body
+func (co *captureOptions) logFasthttpRequestCapture(
+	span opentracing.Span, req *fasthttp.Request,
+) {
+
+	var header = http.Header{}
+	req.Header.VisitAll(func(key, value []byte) {
+		header.Add(string(key), string(value))
+	})
+	co.logHttpCapture(
+		span, logEventHttpRequest, header, co.captureBytes(req.Body()),
+	)
+}
+
+// logFasthttpResponseCapture 记录 fasthttp.Response 的http头及body
+func (co *captureOptions) logFasthttpResponseCapture(
+	span opentracing.Span, resp *fasthttp.Response,
+) {
+
+	var header = getFasthttpRespHeader(resp)
+	var body *captureBuffer
+	if !resp.IsBodyStream() {
+		body = co.captureBytes(resp.Body())
+	}
+	co.logHttpCapture(span, logEventHttpResponse, header, body)
+}
+
+// captureBytes 将完整的body记录到 captureBuffer 中, 未开启body记录时返回nil
+func (co *captureOptions) captureBytes(body []byte) (buf *captureBuffer) {
+
+	if buf = co.newBodyBuffer(); buf != nil {
+		buf.Write(body)
+	}
+	return
+}
+
+// allowMediaType 判断mediaType是否在 WithCaptureContentTypes 设置的范围内
+func (co *captureOptions) allowMediaType(mediaType string) bool {
+
+	for _, contentType := range co.contentTypes {
+		if strings.HasSuffix(contentType, "/") {
+			if strings.HasPrefix(mediaType, contentType) {
+				return true
+			}
+		} else if mediaType == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+func (co *captureOptions) redact(key, value string) string {
+
+	if co.redactor == nil {
+		return value
+	}
+	return co.redactor(key, value)
+}
+
+// redactBody 对json及form格式的body脱敏, 其余格式无法脱敏, 返回 unredactableBody; 未设置 Redactor 时原样返回
+func (co *captureOptions) redactBody(mediaType string, body []byte) string {
+
+	if co.redactor == nil {
+		return string(body)
+	}
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var v interface{}
+		if err := captureJsonSerializer.Unmarshal(body, &v); err != nil {
+			return unparseableJsonBody
+		}
+		var redacted, err = captureJsonSerializer.Marshal(
+			co.redactJsonValue(emptyString, v),
+		)
+		if err != nil {
+			return unparseableJsonBody
+		}
+		return string(redacted)
+	case mediaType == "application/x-www-form-urlencoded":
+		var values, err = url.ParseQuery(string(body))
+		if err != nil {
+			return unredactableBody
+		}
+		for key, vals := range values {
+			for i, val := range vals {
+				vals[i] = co.redactor(key, val)
+			}
+		}
+		return values.Encode()
+	default:
+		return unredactableBody
+	}
+}
+
+// redactJsonValue 递归地对json中的字段脱敏, 数组中的元素使用数组所在字段的名称; 值为对象或数组的字段先以空字符串作为value调用 Redactor, 返回非空值时整个对象或数组被替换, 不再递归
+func (co *captureOptions) redactJsonValue(key string, v interface{}) interface{} {
+
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		if redacted := co.redactor(key, emptyString); redacted != emptyString {
+			return redacted
+		}
+	}
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = co.redactJsonValue(k, item)
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = co.redactJsonValue(key, item)
+		}
+	default:
+		var s = fmt.Sprint(val)
+		if redacted := co.redactor(key, s); redacted != s {
+			return redacted
+		}
+	}
+	return v
+}
+
+// getMediaType 获取Content-Type中不含参数的小写的媒体类型
+func getMediaType(contentType string) string {
+
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+// captureBuffer 记录body的前max个字节
+type captureBuffer struct {
+	buf       []byte
+	max       int
+	truncated bool
+}
+
+func (cb *captureBuffer) Write(p []byte) (n int, err error) {
+
+	n = len(p)
+	if remain := cb.max - len(cb.buf); remain < len(p) {
+		p = p[:remain]
+		cb.truncated = true
+	}
+	cb.buf = append(cb.buf, p...)
+	return
+}
+
+// captureReadCloser 记录handler从request.Body中读取的内容
+type captureReadCloser struct {
+	io.ReadCloser
+	body *captureBuffer
+}
+
+func (crc *captureReadCloser) Read(p []byte) (n int, err error) {
+
+	n, err = crc.ReadCloser.Read(p)
+	crc.body.Write(p[:n])
+	return
+}
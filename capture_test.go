@@ -0,0 +1,235 @@
+package tracer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/uber/jaeger-client-go"
+)
+
+// getCaptureLogs 按event获取span中记录的http头及body
+func getCaptureLogs(span *jaeger.Span) (logs map[string]map[string]interface{}) {
+
+	logs = map[string]map[string]interface{}{}
+	for _, log := range span.Logs() {
+		var fields = map[string]interface{}{}
+		for _, field := range log.Fields {
+			fields[field.Key()] = field.Value()
+		}
+		if event, _ := fields["event"].(string); event == logEventHttpRequest ||
+			event == logEventHttpResponse {
+			logs[event] = fields
+		}
+	}
+	return
+}
+
+func TestHttpMiddleWareCapture(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         []Option
+		reqType      string
+		reqBody      string
+		respType     string
+		respBody     string
+		wantReq      map[string]interface{}
+		wantResp     map[string]interface{}
+		wantNoLogs   bool
+		wantNotFound []string
+	}{
+		{
+			name:       "off by default",
+			reqType:    "application/json",
+			reqBody:    `{"name":"a"}`,
+			wantNoLogs: true,
+		},
+		{
+			name:       "unsampled",
+			opts:       []Option{WithConstSampler(false), WithCaptureHeaders(true), WithCaptureBodies(true, 0)},
+			reqType:    "application/json",
+			reqBody:    `{"name":"a"}`,
+			wantNoLogs: true,
+		},
+		{
+			name:     "redact json and headers",
+			opts:     []Option{WithCaptureHeaders(true), WithCaptureBodies(true, 0)},
+			reqType:  "application/json; charset=utf-8",
+			reqBody:  `{"user":{"name":"a","Password":"p1"},"id":12345678901234567890}`,
+			respType: "text/plain",
+			respBody: "ok",
+			wantReq: map[string]interface{}{
+				"http.header.Authorization": RedactedValue,
+				"http.header.Content-Type":  "application/json; charset=utf-8",
+				logKeyHttpBody:              `{"id":12345678901234567890,"user":{"Password":"[REDACTED]","name":"a"}}`,
+			},
+			wantResp: map[string]interface{}{
+				"http.header.Content-Type": "text/plain",
+				logKeyHttpBody:             unredactableBody,
+			},
+		},
+		{
+			name:    "redact json object and array under sensitive key",
+			opts:    []Option{WithCaptureBodies(true, 0)},
+			reqType: "application/json",
+			reqBody: `{"password":{"new":"x","old":"y"},"token":{"value":"t1"},` +
+				`"authorization":["Bearer a"],"items":[{"secret":"s"},{"name":"n"}]}`,
+			wantReq: map[string]interface{}{
+				logKeyHttpBody: `{"authorization":"[REDACTED]","items":[{"secret":"[REDACTED]"},{"name":"n"}],` +
+					`"password":"[REDACTED]","token":"[REDACTED]"}`,
+			},
+			wantResp: map[string]interface{}{},
+		},
+		{
+			name:     "redact form",
+			opts:     []Option{WithCaptureBodies(true, 0)},
+			reqType:  "application/x-www-form-urlencoded",
+			reqBody:  "name=a&token=t1",
+			wantReq:  map[string]interface{}{logKeyHttpBody: "name=a&token=%5BREDACTED%5D"},
+			wantResp: map[string]interface{}{},
+			wantNotFound: []string{
+				"http.header.Authorization",
+			},
+		},
+		{
+			name:     "truncated",
+			opts:     []Option{WithCaptureBodies(true, 4), WithCaptureRedactor(nil)},
+			reqType:  "application/json",
+			reqBody:  `{"password":"p1"}`,
+			respBody: "hello world",
+			wantReq: map[string]interface{}{
+				logKeyHttpBody:          `{"pa`,
+				logKeyHttpBodyTruncated: true,
+			},
+			wantResp: map[string]interface{}{
+				logKeyHttpBody:          "hell",
+				logKeyHttpBodyTruncated: true,
+			},
+		},
+		{
+			name:     "truncated json redacted",
+			opts:     []Option{WithCaptureBodies(true, 4)},
+			reqType:  "application/json",
+			reqBody:  `{"password":"p1"}`,
+			wantReq:  map[string]interface{}{logKeyHttpBody: unparseableJsonBody},
+			wantResp: map[string]interface{}{},
+		},
+		{
+			name:     "unredactable body omitted",
+			opts:     []Option{WithCaptureBodies(true, 0)},
+			reqType:  "application/xml",
+			reqBody:  "<user><password>p1</password></user>",
+			respType: "text/plain",
+			respBody: "token=t1",
+			wantReq:  map[string]interface{}{logKeyHttpBody: unredactableBody},
+			wantResp: map[string]interface{}{logKeyHttpBody: unredactableBody},
+		},
+		{
+			name:     "unredactable body without redactor",
+			opts:     []Option{WithCaptureBodies(true, 0), WithCaptureRedactor(nil)},
+			reqType:  "application/xml",
+			reqBody:  "<user><password>p1</password></user>",
+			wantReq:  map[string]interface{}{logKeyHttpBody: "<user><password>p1</password></user>"},
+			wantResp: map[string]interface{}{},
+		},
+		{
+			name:         "content type not allowed",
+			opts:         []Option{WithCaptureBodies(true, 0), WithCaptureContentTypes("text/")},
+			reqType:      "application/json",
+			reqBody:      `{"name":"a"}`,
+			respType:     "application/octet-stream",
+			respBody:     "bin",
+			wantReq:      map[string]interface{}{},
+			wantResp:     map[string]interface{}{},
+			wantNotFound: []string{logKeyHttpBody},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ti, reporter = newTestTracer(t, tt.opts...)
+			ti.HttpMiddleWare(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if _, err := io.ReadAll(r.Body); err != nil {
+					t.Errorf("ReadAll() error = %v", err)
+				}
+				if tt.respType != "" {
+					w.Header().Set("Content-Type", tt.respType)
+				}
+				io.WriteString(w, tt.respBody)
+			})).ServeHTTP(httptest.NewRecorder(), func() *http.Request {
+				var r = httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(tt.reqBody))
+				r.Header.Set("Content-Type", tt.reqType)
+				r.Header.Set("Authorization", "Bearer secret")
+				return r
+			}())
+
+			var logs map[string]map[string]interface{}
+			if spans := reporter.GetSpans(); len(spans) != 0 {
+				// 未采样的span不会上报
+				logs = getCaptureLogs(spans[0].(*jaeger.Span))
+			}
+			if tt.wantNoLogs {
+				if len(logs) != 0 {
+					t.Errorf("logs = %v, want none", logs)
+				}
+				return
+			}
+			for event, want := range map[string]map[string]interface{}{
+				logEventHttpRequest: tt.wantReq, logEventHttpResponse: tt.wantResp,
+			} {
+				var got, ok = logs[event]
+				if !ok {
+					t.Fatalf("%v not logged", event)
+				}
+				for key, val := range want {
+					if got[key] != val {
+						t.Errorf("%v %v = %v, want %v", event, key, got[key], val)
+					}
+				}
+				for _, key := range tt.wantNotFound {
+					if _, found := got[key]; found {
+						t.Errorf("%v %v = %v, want not logged", event, key, got[key])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFasthttpClientCapture(t *testing.T) {
+
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=s1")
+		io.WriteString(w, `{"access_token":"t1","expires_in":3600}`)
+	}))
+	defer srv.Close()
+	var ti, reporter = newTestTracer(t, WithCaptureHeaders(true), WithCaptureBodies(true, 0))
+	if _, _, err := ti.PostJsonFasthttp(
+		context.Background(), srv.URL+"/login",
+		map[string]string{"name": "a", "password": "p1"}, nil, nil,
+	); err != nil {
+		t.Fatalf("PostJsonFasthttp() error = %v", err)
+	}
+
+	var logs = getCaptureLogs(reporter.GetSpans()[0].(*jaeger.Span))
+	var want = map[string]map[string]interface{}{
+		logEventHttpRequest: {
+			"http.header.Content-Type": "application/json",
+			logKeyHttpBody:             `{"name":"a","password":"[REDACTED]"}`,
+		},
+		logEventHttpResponse: {
+			"http.header.Set-Cookie": RedactedValue,
+			logKeyHttpBody:           `{"access_token":"[REDACTED]","expires_in":3600}`,
+		},
+	}
+	for event, fields := range want {
+		for key, val := range fields {
+			if logs[event][key] != val {
+				t.Errorf("%v %v = %v, want %v", event, key, logs[event][key], val)
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package tracer
+
+import (
+	"os"
+
+	jaegerCfg "github.com/uber/jaeger-client-go/config"
+)
+
+const envJaegerEndpoint = "JAEGER_ENDPOINT"
+const envJaegerAgentHost = "JAEGER_AGENT_HOST"
+const envJaegerAgentPort = "JAEGER_AGENT_PORT"
+
+// NewTracerFromEnv 根据环境变量创建Tracer实例, 便于部署时无需修改代码即可调整追踪配置; 支持jaeger标准的环境变量:
+//
+//	JAEGER_DISABLED 为true时关闭追踪, 返回 InitEmptyTracer() 的空tracer
+//	JAEGER_SERVICE_NAME 服务名称, 未设置时使用srvName
+//	JAEGER_ENDPOINT 通过http直连jaeger服务端上报, 如 http://127.0.0.1:14268/api/traces
+//	JAEGER_AGENT_HOST, JAEGER_AGENT_PORT 通过udp上报到jaeger-agent, 未设置JAEGER_ENDPOINT时生效, 此时忽略opts中的 WithCollectorEndpoint
+//	JAEGER_SAMPLER_TYPE, JAEGER_SAMPLER_PARAM 采样策略及参数, 如const/probabilistic/ratelimiting/remote
+//	JAEGER_TAGS 全局tag, 如 key1=value1,key2=${envVar:default}
+//	JAEGER_REPORTER_FLUSH_INTERVAL, JAEGER_REPORTER_MAX_QUEUE_SIZE 上报间隔及队列长度
+//
+// 以及jaeger支持的其余JAEGER_*环境变量; 已设置的环境变量优先于opts中的对应配置, 未设置的使用opts或 NewTracer 的默认值, 通过 WithSampler 及 WithReporter 直接指定的采样器及上报器不受环境变量影响
+func NewTracerFromEnv(srvName string, opts ...Option) (
+	tracer Tracer, err error,
+) {
+
+	var o = newOptions(opts...)
+	var cfg = &jaegerCfg.Configuration{
+		ServiceName: srvName,
+		Gen128Bit:   o.gen128Bit,
+	}
+	var samplerConfig = *o.samplerConfig
+	cfg.Sampler = &samplerConfig
+	var reporterConfig = *o.reporterConfig
+	cfg.Reporter = &reporterConfig
+	if cfg, err = cfg.FromEnv(); err != nil {
+		return
+	}
+	if cfg.Disabled {
+		tracer = InitEmptyTracer()
+		return
+	}
+
+	if os.Getenv(envJaegerEndpoint) == emptyString &&
+		(os.Getenv(envJaegerAgentHost) != emptyString || os.Getenv(envJaegerAgentPort) != emptyString) {
+		// jaeger优先使用CollectorEndpoint, 需清除opts中设置的值, 环境变量中的agent地址才能生效
+		cfg.Reporter.CollectorEndpoint = emptyString
+		cfg.Reporter.User, cfg.Reporter.Password = emptyString, emptyString
+	}
+	o.samplerConfig = cfg.Sampler
+	o.reporterConfig = cfg.Reporter
+	o.tags = append(o.tags, cfg.Tags...)
+	o.gen128Bit = cfg.Gen128Bit
+	tracer, err = newTracerByOptions(cfg.ServiceName, o)
+	return
+}
@@ -0,0 +1,118 @@
+package tracer
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/uber/jaeger-client-go"
+)
+
+func TestNewTracerFromEnv(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		opts        []Option
+		wantNoop    bool
+		wantSampled bool
+		wantTag     string
+	}{
+		{
+			name:     "disabled",
+			env:      map[string]string{"JAEGER_DISABLED": "true"},
+			wantNoop: true,
+		},
+		{
+			name:        "defaults",
+			wantSampled: true,
+		},
+		{
+			name: "sampler and tags from env",
+			env: map[string]string{
+				"JAEGER_SAMPLER_TYPE":  "const",
+				"JAEGER_SAMPLER_PARAM": "0",
+				"JAEGER_TAGS":          "env=test",
+			},
+			wantSampled: false,
+			wantTag:     "env",
+		},
+		{
+			name:        "env overrides options",
+			env:         map[string]string{"JAEGER_SAMPLER_TYPE": "const", "JAEGER_SAMPLER_PARAM": "1"},
+			opts:        []Option{WithConstSampler(false)},
+			wantSampled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			var reporter = jaeger.NewInMemoryReporter()
+			var ti, err = NewTracerFromEnv(
+				"tracer-test", append(tt.opts, WithReporter(reporter))...,
+			)
+			if err != nil {
+				t.Fatalf("NewTracerFromEnv() error = %v", err)
+			}
+			defer ti.Close()
+			if tt.wantNoop {
+				if ti != InitEmptyTracer() {
+					t.Errorf("NewTracerFromEnv() = %v, want noop tracer", ti)
+				}
+				return
+			}
+
+			var span = ti.StartSpan("env-op")
+			span.Finish()
+			if got := span.Context().(jaeger.SpanContext).IsSampled(); got != tt.wantSampled {
+				t.Errorf("IsSampled() = %v, want %v", got, tt.wantSampled)
+			}
+			if tt.wantTag == "" {
+				return
+			}
+			var found bool
+			for _, tag := range span.Tracer().(*jaeger.Tracer).Tags() {
+				found = found || tag.Key == tt.wantTag
+			}
+			if !found {
+				t.Errorf("tracer tag %v not found", tt.wantTag)
+			}
+		})
+	}
+}
+
+func TestNewTracerFromEnvAgentOverridesCollectorEndpoint(t *testing.T) {
+
+	var conn, err = net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket() error = %v", err)
+	}
+	defer conn.Close()
+	var host, port, _ = net.SplitHostPort(conn.LocalAddr().String())
+	t.Setenv("JAEGER_AGENT_HOST", host)
+	t.Setenv("JAEGER_AGENT_PORT", port)
+
+	var ti Tracer
+	if ti, err = NewTracerFromEnv(
+		"tracer-test", WithCollectorEndpoint("http://127.0.0.1:1/api/traces"),
+		WithLogger(NewNopLogger()),
+	); err != nil {
+		t.Fatalf("NewTracerFromEnv() error = %v", err)
+	}
+	ti.StartSpan("env-agent-op").Finish()
+	if err = ti.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	var buf = make([]byte, 65535)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var n int
+	if n, _, err = conn.ReadFrom(buf); err != nil {
+		t.Fatalf("ReadFrom() error = %v", err)
+	}
+	if !bytes.Contains(buf[:n], []byte("env-agent-op")) {
+		t.Errorf("packet does not contain the reported span")
+	}
+}
@@ -1,6 +1,6 @@
 module example-beego
 
-go 1.17
+go 1.21
 
 replace github.com/xiaoyang-chen/tracer => ../../tracer/
 
@@ -8,31 +8,30 @@ import (
 
 	"github.com/astaxie/beego"
 	tracer "github.com/xiaoyang-chen/tracer"
+	"github.com/xiaoyang-chen/tracer/beegotracer"
 )
 
-const (
-	serviceName   = "service-a"
-	tracerSrvHost = "http://127.0.0.1:14268"
-)
+const serviceName = "service-a"
 
 var globalTracer = tracer.InitEmptyTracer()
-var globalTracerSwitch = true
 
 func main() {
 
-	// 1. init tracer
-	if globalTracerSwitch {
-		var err error
-		globalTracer, err = tracer.
-			NewTracerBySrvNameAndTracerSrvHost(serviceName, tracerSrvHost)
-		if err != nil {
-			panic(err.Error())
-		}
-		defer globalTracer.Close()
+	// 1. init tracer, 通过JAEGER_*环境变量配置, 设置JAEGER_DISABLED=true时关闭追踪
+	var err error
+	if globalTracer, err = tracer.NewTracerFromEnv(
+		serviceName, tracer.WithLogger(beegotracer.NewLogger(nil)),
+		// 在响应头X-Trace-Id中回写traceID, 便于根据客户端提供的traceID查找请求
+		tracer.WithTraceIDResponseHeader(tracer.TraceIDHeaderName),
+	); err != nil {
+		panic(err.Error())
 	}
+	defer globalTracer.Close()
 	// 2. set router
 	beego.Router("/ping", &pingController{}, "get:Ping")
-	// 3. use the middleware
+	// 3. name spans after the router pattern and the controller method
+	beegotracer.InsertFilter(globalTracer)
+	// 4. use the middleware
 	beego.RunWithMiddleWares("localhost:8081", globalTracer.HttpMiddleWare)
 }
 
@@ -43,12 +42,11 @@ func (c *pingController) Ping() {
 	var ctx = c.Ctx.Request.Context()
 	var respBody []byte
 	var err error
-	ctx, respBody, err = pongService(ctx, 1*time.Second)
-	if err != nil {
+	if _, respBody, err = pongService(ctx, 1*time.Second); err != nil {
 		respBody = []byte(err.Error())
 	}
 
-	c.Success(ctx, respBody)
+	c.Success(respBody)
 }
 
 func pongService(ctx context.Context, sleep time.Duration) (
@@ -80,11 +78,12 @@ func otherService(ctx context.Context, args ...interface{}) (
 	return
 }
 
-type baseController struct{ beego.Controller }
+// baseController 嵌入 beegotracer.Controller, span以 控制器名.方法名 命名
+type baseController struct{ beegotracer.Controller }
 
-func (c *baseController) Success(ctx context.Context, body []byte) {
+// Success traceID由 WithTraceIDResponseHeader 写入响应头X-Trace-Id, 无需手动注入
+func (c *baseController) Success(body []byte) {
 
-	globalTracer.Inject2HttpHeaderByCtx(ctx, c.Ctx.ResponseWriter.Header())
 	c.Ctx.ResponseWriter.WriteHeader(http.StatusOK)
 	c.Ctx.WriteString(fmt.Sprintf("%s -> %s", serviceName, string(body)))
 }
@@ -8,31 +8,28 @@ import (
 
 	"github.com/astaxie/beego"
 	tracer "github.com/xiaoyang-chen/tracer"
+	"github.com/xiaoyang-chen/tracer/beegotracer"
 )
 
-const (
-	serviceName   = "service-b"
-	tracerSrvHost = "http://127.0.0.1:14268"
-)
+const serviceName = "service-b"
 
 var globalTracer = tracer.InitEmptyTracer()
-var globalTracerSwitch = true
 
 func main() {
 
-	// 1. init tracer
-	if globalTracerSwitch {
-		var err error
-		globalTracer, err = tracer.
-			NewTracerBySrvNameAndTracerSrvHost(serviceName, tracerSrvHost)
-		if err != nil {
-			panic(err.Error())
-		}
-		defer globalTracer.Close()
+	// 1. init tracer, 通过JAEGER_*环境变量配置, 设置JAEGER_DISABLED=true时关闭追踪
+	var err error
+	if globalTracer, err = tracer.NewTracerFromEnv(
+		serviceName, tracer.WithLogger(beegotracer.NewLogger(nil)),
+	); err != nil {
+		panic(err.Error())
 	}
+	defer globalTracer.Close()
 	// 2. set router
 	beego.Router("/pong", &pongController{}, "get:Pong")
-	// 3. use the middleware
+	// 3. name spans after the router pattern and the controller method
+	beegotracer.InsertFilter(globalTracer)
+	// 4. use the middleware
 	beego.RunWithMiddleWares("localhost:8082", globalTracer.HttpMiddleWare)
 }
 
@@ -65,7 +62,8 @@ func otherService(ctx context.Context, args ...interface{}) (
 	return
 }
 
-type baseController struct{ beego.Controller }
+// baseController 嵌入 beegotracer.Controller, span以 控制器名.方法名 命名
+type baseController struct{ beegotracer.Controller }
 
 func (c *baseController) Success(ctx context.Context, body []byte) {
 
@@ -0,0 +1,120 @@
+package tracer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// ErrInvalidCAFile FasthttpClientConfig.CAFile 中没有可用的PEM格式证书
+var ErrInvalidCAFile = errors.New("tracer: no valid PEM certificates in CA file")
+
+// ErrIncompleteClientCert FasthttpClientConfig 的 CertFile 与 KeyFile 只设置了其中一个
+var ErrIncompleteClientCert = errors.New("tracer: CertFile and KeyFile must be set together")
+
+// defaultFasthttpClient noopTracerImpl 及未设置 WithFasthttpClientConfig 时使用的 fasthttp.Client, 校验服务端证书
+var defaultFasthttpClient = &fasthttp.Client{}
+
+// FasthttpClientConfig GetFasthttp, PostJsonFasthttp 等方法共用的 fasthttp.Client 的配置, 通过 WithFasthttpClientConfig 设置; 各字段为零值时使用默认值
+type FasthttpClientConfig struct {
+	// Timeout 单个请求的超时时间, 默认为60秒
+	Timeout time.Duration
+	// MaxConnsPerHost 每个host的最大连接数, 默认为 fasthttp.DefaultMaxConnsPerHost
+	MaxConnsPerHost int
+	// MaxConnWaitTimeout 连接数达到 MaxConnsPerHost 时等待空闲连接的最长时间, 默认不等待, 直接返回 fasthttp.ErrNoFreeConns
+	MaxConnWaitTimeout time.Duration
+	// MaxIdleConnDuration 空闲连接的最长保留时间, 默认为 fasthttp.DefaultMaxIdleConnDuration
+	MaxIdleConnDuration time.Duration
+	// TLSConfig https请求的基础TLS配置, 会被复制后再应用 CAFile, CertFile, KeyFile 及 InsecureSkipVerify
+	TLSConfig *tls.Config
+	// CAFile PEM格式的CA证书文件, 其中的证书将追加到系统的根证书中用于校验服务端证书
+	CAFile string
+	// CertFile, KeyFile PEM格式的客户端证书及私钥文件, 用于双向TLS认证, 需同时设置
+	CertFile, KeyFile string
+	// InsecureSkipVerify 为true时不校验服务端证书, 默认校验
+	InsecureSkipVerify bool
+	// Dial 自定义建立连接的方式, 如通过代理访问: fasthttpproxy.FasthttpHTTPDialer("127.0.0.1:8080"), 默认直连
+	Dial fasthttp.DialFunc
+}
+
+// WithFasthttpClientConfig 设置 GetFasthttp, PostJsonFasthttp 等方法共用的 fasthttp.Client, 该client在tracer创建时生成并复用连接, tracer Close 时关闭空闲连接; 证书文件读取失败时 NewTracer 返回对应的错误
+func WithFasthttpClientConfig(clientConfig *FasthttpClientConfig) Option {
+	return func(opts *options) {
+		if clientConfig != nil {
+			var cfg = *clientConfig
+			opts.fasthttpClientConfig = &cfg
+		}
+	}
+}
+
+// newFasthttpClient 根据cfg创建 fasthttp.Client 并返回请求的超时时间
+func newFasthttpClient(cfg *FasthttpClientConfig) (
+	client *fasthttp.Client, timeout time.Duration, err error,
+) {
+
+	if timeout = cfg.Timeout; timeout <= 0 {
+		timeout = httpClientTimeOut
+	}
+	var tlsConfig *tls.Config
+	if tlsConfig, err = newFasthttpClientTLSConfig(cfg); err != nil {
+		return
+	}
+	client = &fasthttp.Client{
+		MaxConnsPerHost:     cfg.MaxConnsPerHost,
+		MaxConnWaitTimeout:  cfg.MaxConnWaitTimeout,
+		MaxIdleConnDuration: cfg.MaxIdleConnDuration,
+		TLSConfig:           tlsConfig,
+		Dial:                cfg.Dial,
+	}
+	return
+}
+
+// newFasthttpClientTLSConfig 根据cfg生成TLS配置, 没有任何TLS相关设置时返回nil, 使用fasthttp的默认配置
+func newFasthttpClientTLSConfig(cfg *FasthttpClientConfig) (
+	tlsConfig *tls.Config, err error,
+) {
+
+	if (cfg.CertFile == emptyString) != (cfg.KeyFile == emptyString) {
+		err = ErrIncompleteClientCert
+		return
+	}
+	if cfg.TLSConfig == nil && cfg.CAFile == emptyString &&
+		cfg.CertFile == emptyString && !cfg.InsecureSkipVerify {
+		return
+	}
+	if cfg.TLSConfig == nil {
+		tlsConfig = &tls.Config{}
+	} else {
+		tlsConfig = cfg.TLSConfig.Clone()
+	}
+	if cfg.CAFile != emptyString {
+		var pem []byte
+		if pem, err = os.ReadFile(cfg.CAFile); err != nil {
+			return
+		}
+		if tlsConfig.RootCAs == nil {
+			if tlsConfig.RootCAs, err = x509.SystemCertPool(); err != nil {
+				tlsConfig.RootCAs, err = x509.NewCertPool(), nil
+			}
+		}
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			err = ErrInvalidCAFile
+			return
+		}
+	}
+	if cfg.CertFile != emptyString {
+		var cert tls.Certificate
+		if cert, err = tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile); err != nil {
+			return
+		}
+		tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
+	}
+	if cfg.InsecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true
+	}
+	return
+}
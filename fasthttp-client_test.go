@@ -0,0 +1,145 @@
+package tracer
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/uber/jaeger-client-go"
+	"github.com/valyala/fasthttp"
+)
+
+func TestFasthttpClientConfig(t *testing.T) {
+
+	var srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	var dir = t.TempDir()
+	var caFile = filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{
+		Type: "CERTIFICATE", Bytes: srv.Certificate().Raw,
+	}), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	var invalidCAFile = filepath.Join(dir, "invalid.pem")
+	if err := os.WriteFile(invalidCAFile, []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	var dials int32
+	var dial = func(addr string) (net.Conn, error) {
+		atomic.AddInt32(&dials, 1)
+		return fasthttp.Dial(addr)
+	}
+	tests := []struct {
+		name          string
+		cfg           *FasthttpClientConfig
+		wantNewErr    error
+		wantReqErr    bool
+		wantDialCount int32
+	}{
+		{name: "verify by default", wantReqErr: true},
+		{name: "ca file", cfg: &FasthttpClientConfig{CAFile: caFile}},
+		{name: "insecure skip verify", cfg: &FasthttpClientConfig{InsecureSkipVerify: true}},
+		{name: "invalid ca file", cfg: &FasthttpClientConfig{CAFile: invalidCAFile}, wantNewErr: ErrInvalidCAFile},
+		{
+			name:       "missing ca file",
+			cfg:        &FasthttpClientConfig{CAFile: filepath.Join(dir, "missing.pem")},
+			wantNewErr: os.ErrNotExist,
+		},
+		{
+			name:       "cert file without key file",
+			cfg:        &FasthttpClientConfig{CertFile: caFile},
+			wantNewErr: ErrIncompleteClientCert,
+		},
+		{
+			name:       "key file without cert file",
+			cfg:        &FasthttpClientConfig{KeyFile: caFile},
+			wantNewErr: ErrIncompleteClientCert,
+		},
+		{
+			name:          "dial reuses connection",
+			cfg:           &FasthttpClientConfig{CAFile: caFile, Dial: dial, MaxConnsPerHost: 1},
+			wantDialCount: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&dials, 0)
+			var tracer, err = NewTracer(
+				"tracer-test", WithReporter(jaeger.NewNullReporter()), WithLogger(NewNopLogger()),
+				WithFasthttpClientConfig(tt.cfg),
+			)
+			if !errors.Is(err, tt.wantNewErr) {
+				t.Fatalf("NewTracer() error = %v, want %v", err, tt.wantNewErr)
+			}
+			if err != nil {
+				return
+			}
+			defer tracer.Close()
+			for i := 0; i < 3; i++ {
+				if _, _, err = tracer.GetFasthttp(
+					context.Background(), srv.URL, nil, nil,
+				); (err != nil) != tt.wantReqErr {
+					t.Fatalf("GetFasthttp() error = %v, wantReqErr %v", err, tt.wantReqErr)
+				}
+			}
+			if tt.wantDialCount != 0 && atomic.LoadInt32(&dials) != tt.wantDialCount {
+				t.Errorf("dials = %v, want %v", dials, tt.wantDialCount)
+			}
+		})
+	}
+}
+
+func TestFasthttpClientCloseIdleConnections(t *testing.T) {
+
+	var closed = make(chan struct{}, 1)
+	var srv = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			closed <- struct{}{}
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	var tracer, err = NewTracer(
+		"tracer-test", WithReporter(jaeger.NewNullReporter()), WithLogger(NewNopLogger()),
+	)
+	if err != nil {
+		t.Fatalf("NewTracer() error = %v", err)
+	}
+	if _, _, err = tracer.GetFasthttp(context.Background(), srv.URL, nil, nil); err != nil {
+		t.Fatalf("GetFasthttp() error = %v", err)
+	}
+	if err = tracer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Error("idle connection not closed by Close()")
+	}
+}
+
+func TestFasthttpClientTimeout(t *testing.T) {
+
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer srv.Close()
+	var ti, _ = newTestTracer(t, WithFasthttpClientConfig(
+		&FasthttpClientConfig{Timeout: 20 * time.Millisecond},
+	))
+	if _, _, err := ti.GetFasthttp(
+		context.Background(), srv.URL, nil, nil,
+	); err != fasthttp.ErrTimeout {
+		t.Errorf("GetFasthttp() error = %v, want %v", err, fasthttp.ErrTimeout)
+	}
+}
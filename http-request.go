@@ -2,7 +2,6 @@ package tracer
 
 import (
 	"context"
-	"crypto/tls"
 	"net/http"
 	"strings"
 	"time"
@@ -12,7 +11,6 @@ import (
 )
 
 const httpClientTimeOut = 60 * time.Second
-const tlsConfigInsecureSkipVerify = true
 const defaultUrlLength = 256
 
 var jsonSerializer serializer = jsoniter.ConfigCompatibleWithStandardLibrary
@@ -101,34 +99,75 @@ func (ti *tracerImpl) fasthttpReq(
 	mapHeader, mapCookie map[string]string, cbs ...FasthttpRespCallback,
 ) (newCtx context.Context, respBody []byte, err error) {
 
-	var childSpan = ti.ChildSpanFromContext(url, ctx)
+	var response *Response
+	if response, err = ti.doFasthttpReq(
+		ctx, url, nil, func(req *fasthttp.Request) (err error) {
+
+			if jsonData != nil {
+				if err = setFasthttpReqBodyByJsonData(req, jsonData); err != nil {
+					return
+				}
+				req.Header.Set("Content-Type", "application/json")
+			}
+			req.SetRequestURI(url)
+			req.Header.SetMethod(method)
+			setFasthttpReqHeaderByMap(req, mapHeader)
+			setFasthttpReqCookiesByMap(req, mapCookie)
+			return
+		}, cbs...,
+	); err != nil {
+		return
+	}
+	newCtx, respBody = response.Ctx, response.Body
+	return
+}
+
+// doFasthttpReq 发送请求的公共流程: 根据ctx中的span信息创建名为opName的子span并打进请求头里, 通过prepare设置请求的url, 方法, 头及body等内容, 发送请求并记录tag, 然后从响应头中获取下游返回的span信息存入 Response.Ctx; validate不为nil时用于校验响应, 校验失败时span设置 error=true, 并同时返回response及该错误
+func (ti *tracerImpl) doFasthttpReq(
+	ctx context.Context, opName string, validate func(response *Response) error,
+	prepare func(req *fasthttp.Request) (err error), cbs ...FasthttpRespCallback,
+) (response *Response, err error) {
+
+	var childSpan = ti.ChildSpanFromContext(opName, ctx)
 	defer childSpan.Finish()
 
 	var req = fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(req)
+	var resp = fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
 	if err = ti.Inject2FasthttpHeader(childSpan, &req.Header); err != nil {
 		return
 	}
-	if jsonData != nil {
-		if err = setFasthttpReqBodyByJsonData(req, jsonData); err != nil {
-			return
-		}
-		req.Header.Set("Content-Type", "application/json")
+	if err = prepare(req); err != nil {
+		return
+	}
+	setFasthttpClientSpanTags(childSpan, req)
+	var capture = ti.capture.shouldCapture(childSpan)
+	if capture {
+		ti.capture.logFasthttpRequestCapture(childSpan, req)
 	}
-	req.SetRequestURI(url)
-	req.Header.SetMethod(method)
-	setFasthttpReqHeaderByMap(req, mapHeader)
-	setFasthttpReqCookiesByMap(req, mapCookie)
 
-	var resp = fasthttp.AcquireResponse()
-	defer fasthttp.ReleaseResponse(resp)
-	if err = sendFasthttpReqWithTimeOut(req, resp); err != nil {
+	err = ti.sendFasthttpReqWithTimeOut(ctx, req, resp)
+	setFasthttpClientRespSpanTags(childSpan, resp, err)
+	if err != nil {
 		return
 	}
-	newCtx = ti.CtxWithSpanCtxFromFasthttpHeader(ctx, &resp.Header)
+	if capture {
+		ti.capture.logFasthttpResponseCapture(childSpan, resp)
+	}
+	response = &Response{
+		Ctx:        ti.CtxWithSpanCtxFromFasthttpHeader(ctx, &resp.Header),
+		StatusCode: resp.StatusCode(),
+		Header:     getFasthttpRespHeader(resp),
+	}
 
-	applyFasthttpRespCallback(newCtx, resp, cbs...)
-	respBody = getFasthttpRespBody(resp)
+	applyFasthttpRespCallback(response.Ctx, resp, cbs...)
+	response.Body = getFasthttpRespBody(resp)
+	if validate != nil {
+		if err = validate(response); err != nil {
+			setHttpClientErrorSpanTags(childSpan, err)
+		}
+	}
 	return
 }
 
@@ -161,13 +200,56 @@ func setFasthttpReqBodyByJsonData(
 	req.SetBody(body)
 	return
 }
-func sendFasthttpReqWithTimeOut(
-	req *fasthttp.Request, resp *fasthttp.Response,
+
+// sendFasthttpReqWithTimeOut 发送请求, 截止时间取ctx的deadline与配置的超时时间中较早的一个; ctx可被取消时, 请求使用req及resp的副本在另一个goroutine中进行, ctx被取消时立即返回ctx.Err(), 副本由该goroutine在请求结束后释放
+func (ti *tracerImpl) sendFasthttpReqWithTimeOut(
+	ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response,
 ) (err error) {
 
-	err = (&fasthttp.Client{
-		TLSConfig: &tls.Config{InsecureSkipVerify: tlsConfigInsecureSkipVerify},
-	}).DoTimeout(req, resp, httpClientTimeOut)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	var deadline = time.Now().Add(ti.fasthttpTimeout)
+	var byDeadline bool
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline, byDeadline = ctxDeadline, true
+	}
+	if ctx.Done() == nil {
+		// ctx不会被取消, 无需额外的goroutine
+		err = ti.fasthttpClient.DoDeadline(req, resp, deadline)
+		return
+	}
+
+	var reqCopy = fasthttp.AcquireRequest()
+	req.CopyTo(reqCopy)
+	var respCopy = fasthttp.AcquireResponse()
+	var done = make(chan error, 1)
+	go func() { done <- ti.fasthttpClient.DoDeadline(reqCopy, respCopy, deadline) }()
+	select {
+	case err = <-done:
+		respCopy.CopyTo(resp)
+		fasthttp.ReleaseRequest(reqCopy)
+		fasthttp.ReleaseResponse(respCopy)
+	case <-ctx.Done():
+		err = ctx.Err()
+		go func() {
+			<-done
+			fasthttp.ReleaseRequest(reqCopy)
+			fasthttp.ReleaseResponse(respCopy)
+		}()
+	}
+	if err == nil {
+		return
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		err = ctxErr
+	} else if err == fasthttp.ErrTimeout && byDeadline {
+		// 截止时间取自ctx的deadline时, 请求可能先于ctx超时返回
+		err = context.DeadlineExceeded
+	}
 	return
 }
 
@@ -187,3 +269,13 @@ func getFasthttpRespBody(resp *fasthttp.Response) (body []byte) {
 	copy(body, respBody)
 	return
 }
+
+// getFasthttpRespHeader 将 fasthttp.ResponseHeader 复制为 http.Header, 保留同名的多个值
+func getFasthttpRespHeader(resp *fasthttp.Response) (header http.Header) {
+
+	header = http.Header{}
+	resp.Header.VisitAll(func(key, value []byte) {
+		header.Add(string(key), string(value))
+	})
+	return
+}
@@ -2,8 +2,16 @@ package tracer
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/uber/jaeger-client-go"
+	"github.com/valyala/fasthttp"
 )
 
 func TestMakeupUrlByHostPathQueryParams(t *testing.T) {
@@ -105,3 +113,103 @@ func TestMakeupUrlByHostPathQueryParams(t *testing.T) {
 		})
 	}
 }
+
+func TestFasthttpReqContext(t *testing.T) {
+
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+	}))
+	defer srv.Close()
+	var cancelled, cancel = context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name    string
+		ctx     func() (context.Context, context.CancelFunc)
+		wantErr error
+		wantTag string
+	}{
+		{
+			name: "cancelled during request",
+			ctx: func() (context.Context, context.CancelFunc) {
+				var ctx, cancel = context.WithCancel(context.Background())
+				time.AfterFunc(20*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			wantErr: context.Canceled,
+			wantTag: tagKeyHttpCancelled,
+		},
+		{
+			name: "cancelled before request",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return cancelled, func() {}
+			},
+			wantErr: context.Canceled,
+			wantTag: tagKeyHttpCancelled,
+		},
+		{
+			name: "deadline shorter than timeout",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 20*time.Millisecond)
+			},
+			wantErr: context.DeadlineExceeded,
+			wantTag: tagKeyHttpTimeout,
+		},
+		{
+			name: "configured timeout shorter than deadline",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), time.Minute)
+			},
+			wantErr: fasthttp.ErrTimeout,
+			wantTag: tagKeyHttpTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ti, reporter = newTestTracer(t, WithFasthttpClientConfig(
+				&FasthttpClientConfig{Timeout: 50 * time.Millisecond},
+			))
+			var ctx, cancel = tt.ctx()
+			defer cancel()
+			var start = time.Now()
+			var _, _, err = ti.PostJsonFasthttp(ctx, srv.URL, map[string]string{"a": "b"}, nil, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("PostJsonFasthttp() error = %v, want %v", err, tt.wantErr)
+			}
+			if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+				t.Errorf("PostJsonFasthttp() took %v, want abort early", elapsed)
+			}
+			var tags = reporter.GetSpans()[0].(*jaeger.Span).Tags()
+			if tags[tt.wantTag] != true || tags[string(ext.Error)] != true {
+				t.Errorf("Tags() = %v, want %v=true", tags, tt.wantTag)
+			}
+		})
+	}
+}
+
+func TestFasthttpReqCancelThenReuse(t *testing.T) {
+
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer srv.Close()
+
+	var ti, _ = newTestTracer(t)
+	var ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	var start = time.Now()
+	if _, _, err := ti.GetFasthttp(ctx, srv.URL+"/slow", nil, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("GetFasthttp() error = %v, want %v", err, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("GetFasthttp() took %v, want abort early", elapsed)
+	}
+	// 被取消的请求仍在后台使用副本进行, 不影响之后的请求
+	var _, body, err = ti.GetFasthttp(context.Background(), srv.URL+"/fast", nil, nil)
+	if err != nil || string(body) != "/fast" {
+		t.Errorf("GetFasthttp() = %q, %v, want /fast", body, err)
+	}
+	time.Sleep(250 * time.Millisecond)
+}
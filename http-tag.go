@@ -0,0 +1,144 @@
+package tracer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	opentracingLog "github.com/opentracing/opentracing-go/log"
+	"github.com/valyala/fasthttp"
+)
+
+// tagKeyHttpUserAgent opentracing的ext中未定义user agent的tag, 与OpenTelemetry的语义约定保持一致
+const tagKeyHttpUserAgent = "http.user_agent"
+
+// tagKeyHttpResponseSize 服务端写入的响应体字节数
+const tagKeyHttpResponseSize = "http.response_size"
+
+// tagKeyHttpCancelled 客户端请求因ctx被取消而中止
+const tagKeyHttpCancelled = "http.cancelled"
+
+// tagKeyHttpTimeout 客户端请求因ctx的deadline或配置的超时时间而中止
+const tagKeyHttpTimeout = "http.timeout"
+
+// setHttpServerSpanTags 按opentracing的语义约定为 HttpMiddleWare 的span设置 span.kind=server, http.method, http.url, peer.*, http.user_agent 等tag
+func setHttpServerSpanTags(span opentracing.Span, r *http.Request) {
+
+	ext.SpanKindRPCServer.Set(span)
+	ext.Component.Set(span, httpMiddleWareComponentName)
+	ext.HTTPMethod.Set(span, r.Method)
+	ext.HTTPUrl.Set(span, r.URL.String())
+	if host, port, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		setPeerSpanTags(span, host, port)
+	}
+	if userAgent := r.UserAgent(); userAgent != emptyString {
+		span.SetTag(tagKeyHttpUserAgent, userAgent)
+	}
+}
+
+// setFasthttpServerSpanTags 同 setHttpServerSpanTags, 用于 FasthttpMiddleWare 的span
+func setFasthttpServerSpanTags(span opentracing.Span, ctx *fasthttp.RequestCtx) {
+
+	ext.SpanKindRPCServer.Set(span)
+	ext.Component.Set(span, httpMiddleWareComponentName)
+	ext.HTTPMethod.Set(span, string(ctx.Method()))
+	ext.HTTPUrl.Set(span, string(ctx.RequestURI()))
+	if addr, ok := ctx.RemoteAddr().(*net.TCPAddr); ok {
+		setPeerSpanTags(span, addr.IP.String(), strconv.Itoa(addr.Port))
+	}
+	if userAgent := ctx.UserAgent(); len(userAgent) != 0 {
+		span.SetTag(tagKeyHttpUserAgent, string(userAgent))
+	}
+}
+
+// setFasthttpClientSpanTags 按opentracing的语义约定为 fasthttpReq 的span设置 span.kind=client, http.method, http.url, http.user_agent 等tag, 对端信息在收到响应后通过 setFasthttpClientRespSpanTags 设置
+func setFasthttpClientSpanTags(span opentracing.Span, req *fasthttp.Request) {
+
+	ext.SpanKindRPCClient.Set(span)
+	ext.Component.Set(span, httpMiddleWareComponentName)
+	ext.HTTPMethod.Set(span, string(req.Header.Method()))
+	ext.HTTPUrl.Set(span, req.URI().String())
+	if userAgent := req.Header.UserAgent(); len(userAgent) != 0 {
+		span.SetTag(tagKeyHttpUserAgent, string(userAgent))
+	}
+}
+
+// setFasthttpClientRespSpanTags 根据请求结果为 fasthttpReq 的span设置 http.status_code, peer.* 等tag, 请求失败或状态码为5xx时设置 error=true
+func setFasthttpClientRespSpanTags(
+	span opentracing.Span, resp *fasthttp.Response, err error,
+) {
+
+	if err != nil {
+		setHttpClientErrorSpanTags(span, err)
+		return
+	}
+	if addr, ok := resp.RemoteAddr().(*net.TCPAddr); ok {
+		setPeerSpanTags(span, addr.IP.String(), strconv.Itoa(addr.Port))
+	}
+	setHttpStatusCodeSpanTag(span, resp.StatusCode())
+}
+
+// setHttpClientSpanTags 按opentracing的语义约定为 HttpRoundTripper 的span设置 span.kind=client, http.method, http.url, http.user_agent 等tag
+func setHttpClientSpanTags(span opentracing.Span, req *http.Request) {
+
+	ext.SpanKindRPCClient.Set(span)
+	ext.Component.Set(span, httpMiddleWareComponentName)
+	ext.HTTPMethod.Set(span, req.Method)
+	ext.HTTPUrl.Set(span, req.URL.String())
+	if userAgent := req.UserAgent(); userAgent != emptyString {
+		span.SetTag(tagKeyHttpUserAgent, userAgent)
+	}
+}
+
+// setHttpClientRespSpanTags 根据请求结果为 HttpRoundTripper 的span设置 http.status_code, peer.* 等tag, 请求失败或状态码为5xx时设置 error=true
+func setHttpClientRespSpanTags(
+	span opentracing.Span, resp *http.Response, err error,
+) {
+
+	if err != nil {
+		setHttpClientErrorSpanTags(span, err)
+		return
+	}
+	setHttpStatusCodeSpanTag(span, resp.StatusCode)
+}
+
+// setHttpClientErrorSpanTags 请求失败时设置 error=true 并记录错误, 因ctx被取消或超时而失败时分别设置 http.cancelled=true 或 http.timeout=true
+func setHttpClientErrorSpanTags(span opentracing.Span, err error) {
+
+	ext.Error.Set(span, true)
+	span.LogFields(opentracingLog.Error(err))
+	if errors.Is(err, context.Canceled) {
+		span.SetTag(tagKeyHttpCancelled, true)
+	} else if errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, fasthttp.ErrTimeout) {
+		span.SetTag(tagKeyHttpTimeout, true)
+	}
+}
+
+// setHttpStatusCodeSpanTag 设置 http.status_code, 状态码为5xx时设置 error=true
+func setHttpStatusCodeSpanTag(span opentracing.Span, statusCode int) {
+
+	ext.HTTPStatusCode.Set(span, uint16(statusCode))
+	if statusCode >= http.StatusInternalServerError {
+		ext.Error.Set(span, true)
+	}
+}
+
+// setPeerSpanTags 根据对端的ip及端口设置 peer.ipv4 或 peer.ipv6 及 peer.port
+func setPeerSpanTags(span opentracing.Span, host, port string) {
+
+	if ip := net.ParseIP(host); ip == nil {
+		ext.PeerHostname.Set(span, host)
+	} else if ip.To4() != nil {
+		ext.PeerHostIPv4.SetString(span, host)
+	} else {
+		ext.PeerHostIPv6.Set(span, host)
+	}
+	if p, err := strconv.ParseUint(port, 10, 16); err == nil {
+		ext.PeerPort.Set(span, uint16(p))
+	}
+}
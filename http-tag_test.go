@@ -0,0 +1,95 @@
+package tracer
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/uber/jaeger-client-go"
+	"github.com/valyala/fasthttp"
+)
+
+func TestHttpMiddleWareSpanTags(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		wantError  bool
+	}{
+		{name: "ok", statusCode: http.StatusOK},
+		{name: "client error", statusCode: http.StatusNotFound},
+		{name: "server error", statusCode: http.StatusBadGateway, wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ti, reporter = newTestTracer(t)
+			var r = httptest.NewRequest(http.MethodPost, "/users/1?name=a", nil)
+			r.RemoteAddr = "10.0.0.1:51234"
+			r.Header.Set("User-Agent", "tracer-test")
+			ti.HttpMiddleWare(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+			})).ServeHTTP(httptest.NewRecorder(), r)
+
+			var tags = reporter.GetSpans()[0].(*jaeger.Span).Tags()
+			var want = map[string]interface{}{
+				string(ext.SpanKind):       ext.SpanKindRPCServerEnum,
+				string(ext.Component):      httpMiddleWareComponentName,
+				string(ext.HTTPMethod):     http.MethodPost,
+				string(ext.HTTPUrl):        "/users/1?name=a",
+				string(ext.HTTPStatusCode): uint16(tt.statusCode),
+				string(ext.PeerHostIPv4):   "10.0.0.1",
+				string(ext.PeerPort):       uint16(51234),
+				tagKeyHttpUserAgent:        "tracer-test",
+			}
+			for key, val := range want {
+				if tags[key] != val {
+					t.Errorf("tag %v = %v, want %v", key, tags[key], val)
+				}
+			}
+			if isError, _ := tags[string(ext.Error)].(bool); isError != tt.wantError {
+				t.Errorf("tag error = %v, want %v", isError, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestFasthttpClientSpanTags(t *testing.T) {
+
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	var ti, reporter = newTestTracer(t)
+	if _, _, err := ti.GetFasthttp(
+		context.Background(), srv.URL+"/pong", map[string]string{"User-Agent": "tracer-test"}, nil,
+	); err != nil {
+		t.Fatalf("GetFasthttp() error = %v", err)
+	}
+
+	var tags = reporter.GetSpans()[0].(*jaeger.Span).Tags()
+	var want = map[string]interface{}{
+		string(ext.SpanKind):       ext.SpanKindRPCClientEnum,
+		string(ext.HTTPMethod):     fasthttp.MethodGet,
+		string(ext.HTTPUrl):        srv.URL + "/pong",
+		string(ext.HTTPStatusCode): uint16(http.StatusServiceUnavailable),
+		string(ext.PeerHostIPv4):   "127.0.0.1",
+		string(ext.PeerPort):       uint16(srv.Listener.Addr().(*net.TCPAddr).Port),
+		string(ext.Error):          true,
+		tagKeyHttpUserAgent:        "tracer-test",
+	}
+	for key, val := range want {
+		if tags[key] != val {
+			t.Errorf("tag %v = %v, want %v", key, tags[key], val)
+		}
+	}
+
+	reporter.Reset()
+	if _, _, err := ti.GetFasthttp(context.Background(), "http://127.0.0.1:1/", nil, nil); err == nil {
+		t.Fatalf("GetFasthttp() error = nil, want connection error")
+	}
+	if tags = reporter.GetSpans()[0].(*jaeger.Span).Tags(); tags[string(ext.Error)] != true {
+		t.Errorf("tag error = %v, want true", tags[string(ext.Error)])
+	}
+}
@@ -0,0 +1,64 @@
+package tracer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+)
+
+// Logger tracer内部信息(如上报失败, 拉取采样策略失败等)的输出接口, 与jaeger的 jaeger.Logger 兼容, 通过 WithLogger 设置
+type Logger interface {
+	// Error 输出错误信息
+	Error(msg string)
+	// Infof 输出普通信息
+	Infof(msg string, args ...interface{})
+}
+
+var defaultNopLogger Logger = nopLogger{}
+
+// NewStdLogger 通过标准库的 *log.Logger 输出, l为nil时使用标准库log的默认logger
+func NewStdLogger(l *log.Logger) Logger {
+
+	if l == nil {
+		l = log.Default()
+	}
+	return &stdLogger{l: l}
+}
+
+// NewSlogLogger 通过 *slog.Logger 输出, Error输出为 slog.LevelError 级别, Infof输出为 slog.LevelInfo 级别, l为nil时使用 slog.Default()
+func NewSlogLogger(l *slog.Logger) Logger {
+
+	if l == nil {
+		l = slog.Default()
+	}
+	return &slogLogger{l: l}
+}
+
+// NewNopLogger 不输出任何信息的Logger
+func NewNopLogger() Logger { return defaultNopLogger }
+
+type stdLogger struct{ l *log.Logger }
+
+func (sl *stdLogger) Error(msg string) { sl.l.Print("ERROR: " + msg) }
+
+func (sl *stdLogger) Infof(msg string, args ...interface{}) {
+	sl.l.Printf(msg, args...)
+}
+
+type slogLogger struct{ l *slog.Logger }
+
+func (sl *slogLogger) Error(msg string) { sl.l.Error(msg) }
+
+func (sl *slogLogger) Infof(msg string, args ...interface{}) {
+
+	if !sl.l.Enabled(context.Background(), slog.LevelInfo) {
+		return
+	}
+	sl.l.Info(fmt.Sprintf(msg, args...))
+}
+
+type nopLogger struct{}
+
+func (nl nopLogger) Error(msg string)                      {}
+func (nl nopLogger) Infof(msg string, args ...interface{}) {}
@@ -0,0 +1,54 @@
+package tracer
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLoggers(t *testing.T) {
+
+	var buf bytes.Buffer
+	tests := []struct {
+		name      string
+		logger    Logger
+		wantError string
+		wantInfo  string
+	}{
+		{
+			name:      "std logger",
+			logger:    NewStdLogger(log.New(&buf, "", 0)),
+			wantError: "ERROR: report failed 100%\n",
+			wantInfo:  "sampler updated: 0.5\n",
+		},
+		{
+			name:      "slog logger",
+			logger:    NewSlogLogger(slog.New(slog.NewTextHandler(&buf, nil))),
+			wantError: `level=ERROR msg="report failed 100%"`,
+			wantInfo:  `level=INFO msg="sampler updated: 0.5"`,
+		},
+		{
+			name:   "nop logger",
+			logger: NewNopLogger(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			tt.logger.Error("report failed 100%")
+			if got := buf.String(); !strings.Contains(got, tt.wantError) {
+				t.Errorf("Error() wrote %q, want %q", got, tt.wantError)
+			}
+			buf.Reset()
+			tt.logger.Infof("sampler updated: %v", 0.5)
+			if got := buf.String(); !strings.Contains(got, tt.wantInfo) {
+				t.Errorf("Infof() wrote %q, want %q", got, tt.wantInfo)
+			}
+			if tt.wantError == "" && buf.Len() != 0 {
+				t.Errorf("nop logger wrote %q", buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package tracer
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// pathPlaceholderID OperationNameNormalizedPath 替换纯数字路径段使用的占位符
+const pathPlaceholderID = "{id}"
+
+// pathPlaceholderUUID OperationNameNormalizedPath 替换UUID路径段使用的占位符
+const pathPlaceholderUUID = "{uuid}"
+
+var uuidRegexp = regexp.MustCompile(
+	`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`,
+)
+
+// OperationNameFunc 根据http请求生成 HttpMiddleWare 中span的操作名称, 通过 WithOperationNameFunc 设置, 不作用于 FasthttpMiddleWare
+type OperationNameFunc func(r *http.Request) (opName string)
+
+// WithOperationNameFunc 设置 HttpMiddleWare 中span的操作名称生成方式, 默认为 OperationNameMethodPath; 只作用于 HttpMiddleWare, FasthttpMiddleWare 的操作名称固定为 "HTTP 请求方法 请求路径"; 路径中带有id等参数时每个取值都会成为jaeger中单独的操作, 可使用 OperationNameServeMuxPattern 或 OperationNameNormalizedPath 降低操作名称的数量
+func WithOperationNameFunc(operationNameFunc OperationNameFunc) Option {
+	return func(opts *options) {
+		if operationNameFunc != nil {
+			opts.operationNameFunc = operationNameFunc
+		}
+	}
+}
+
+// OperationNameMethodPath 以 "HTTP 请求方法 请求路径" 命名, example: HTTP GET /users/123
+func OperationNameMethodPath(r *http.Request) (opName string) {
+
+	opName = getOperationNameFromHttpRequest(r)
+	return
+}
+
+// OperationNameServeMuxPattern 以 "HTTP 请求方法 mux中匹配到的路由规则" 命名, example: 通过 mux.Handle("/users/", h) 注册时, 请求/users/123的操作名称为 HTTP GET /users/; mux中没有匹配的路由规则时使用 OperationNameMethodPath
+func OperationNameServeMuxPattern(mux *http.ServeMux) OperationNameFunc {
+
+	return func(r *http.Request) (opName string) {
+
+		var _, pattern = mux.Handler(r)
+		if pattern == emptyString {
+			opName = getOperationNameFromHttpRequest(r)
+		} else if strings.HasPrefix(pattern, r.Method+" ") {
+			// 路由规则本身带有请求方法时不再重复添加
+			opName = "HTTP " + pattern
+		} else {
+			opName = "HTTP " + r.Method + " " + pattern
+		}
+		return
+	}
+}
+
+// OperationNameNormalizedPath 以 "HTTP 请求方法 规范化后的请求路径" 命名, 规范化时将纯数字的路径段替换为{id}, UUID格式的路径段替换为{uuid}, example: 请求/users/123/orders/3f2b8a9e-0c1d-4e5f-8a7b-6c5d4e3f2a1b的操作名称为 HTTP GET /users/{id}/orders/{uuid}
+func OperationNameNormalizedPath(r *http.Request) (opName string) {
+
+	opName = "HTTP " + r.Method + " " + normalizePath(r.URL.Path)
+	return
+}
+
+// normalizePath 将path中纯数字及UUID格式的路径段替换为占位符
+func normalizePath(path string) string {
+
+	var segments = strings.Split(path, "/")
+	for i, segment := range segments {
+		if isDigits(segment) {
+			segments[i] = pathPlaceholderID
+		} else if uuidRegexp.MatchString(segment) {
+			segments[i] = pathPlaceholderUUID
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// isDigits 判断s是否为非空的纯数字字符串
+func isDigits(s string) bool {
+
+	if s == emptyString {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
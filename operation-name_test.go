@@ -0,0 +1,90 @@
+package tracer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/uber/jaeger-client-go"
+)
+
+func TestOperationNameFunc(t *testing.T) {
+
+	var mux = http.NewServeMux()
+	mux.HandleFunc("/users/", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/ping", func(w http.ResponseWriter, r *http.Request) {})
+	tests := []struct {
+		name              string
+		operationNameFunc OperationNameFunc
+		method            string
+		url               string
+		want              string
+	}{
+		{
+			name:   "default",
+			method: http.MethodGet,
+			url:    "/users/123?name=a",
+			want:   "HTTP GET /users/123",
+		},
+		{
+			name:              "method path",
+			operationNameFunc: OperationNameMethodPath,
+			method:            http.MethodPost,
+			url:               "/users/123",
+			want:              "HTTP POST /users/123",
+		},
+		{
+			name:              "serve mux pattern",
+			operationNameFunc: OperationNameServeMuxPattern(mux),
+			method:            http.MethodGet,
+			url:               "/users/123",
+			want:              "HTTP GET /users/",
+		},
+		{
+			name:              "serve mux exact pattern",
+			operationNameFunc: OperationNameServeMuxPattern(mux),
+			method:            http.MethodGet,
+			url:               "/ping",
+			want:              "HTTP GET /ping",
+		},
+		{
+			name:              "serve mux not found",
+			operationNameFunc: OperationNameServeMuxPattern(mux),
+			method:            http.MethodGet,
+			url:               "/unknown/1",
+			want:              "HTTP GET /unknown/1",
+		},
+		{
+			name:              "normalized path",
+			operationNameFunc: OperationNameNormalizedPath,
+			method:            http.MethodDelete,
+			url:               "/users/123/orders/3f2b8a9e-0c1d-4e5f-8a7b-6c5d4e3f2a1b/items/v2",
+			want:              "HTTP DELETE /users/{id}/orders/{uuid}/items/v2",
+		},
+		{
+			name:              "normalized path without params",
+			operationNameFunc: OperationNameNormalizedPath,
+			method:            http.MethodGet,
+			url:               "/",
+			want:              "HTTP GET /",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.operationNameFunc != nil {
+				opts = append(opts, WithOperationNameFunc(tt.operationNameFunc))
+			}
+			var ti, reporter = newTestTracer(t, opts...)
+			ti.HttpMiddleWare(mux).ServeHTTP(
+				httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.url, nil),
+			)
+			if got := reporter.SpansSubmitted(); got != 1 {
+				t.Fatalf("SpansSubmitted() = %v, want 1", got)
+			}
+			if got := reporter.GetSpans()[0].(*jaeger.Span).OperationName(); got != tt.want {
+				t.Errorf("OperationName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package tracer
+
+import (
+	"github.com/opentracing/opentracing-go"
+	"github.com/uber/jaeger-client-go"
+	jaegerCfg "github.com/uber/jaeger-client-go/config"
+)
+
+// Option 创建Tracer时的可选配置, 通过 NewTracer 的opts参数传入
+type Option func(opts *options)
+
+type options struct {
+	samplerConfig      *jaegerCfg.SamplerConfig
+	newSampler         func() jaeger.Sampler
+	reporterConfig     *jaegerCfg.ReporterConfig
+	reporter           jaeger.Reporter
+	agentMaxPacketSize int
+	logger             Logger
+	propagator         Propagator
+	tags               []opentracing.Tag
+	gen128Bit          bool
+	poolSpans          bool
+	maxTagValueLength  int
+	operationNameFunc  OperationNameFunc
+	recoverPanic       bool
+	capture            captureOptions
+	traceIDHeaderName  string
+
+	fasthttpClientConfig *FasthttpClientConfig
+
+	skipRequestMatchers        []RequestMatcher
+	forceSampleRequestMatchers []RequestMatcher
+	neverSampleRequestMatchers []RequestMatcher
+}
+
+// newDefaultOptions 默认配置: 全追踪模式(const sampler, param为1), 内部信息通过标准库log的默认logger输出, 使用ot-mw-*系列http头传递span信息
+func newDefaultOptions() (opts *options) {
+
+	opts = &options{
+		samplerConfig: &jaegerCfg.SamplerConfig{
+			Type:  jaeger.SamplerTypeConst,
+			Param: 1,
+		},
+		reporterConfig:    &jaegerCfg.ReporterConfig{},
+		logger:            NewStdLogger(nil),
+		propagator:        NewJaegerPropagator(pJaegerHeaderConfig),
+		operationNameFunc: OperationNameMethodPath,
+		capture:           newDefaultCaptureOptions(),
+
+		fasthttpClientConfig: &FasthttpClientConfig{},
+	}
+	return
+}
+
+func newOptions(opts ...Option) (o *options) {
+
+	o = newDefaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	return
+}
+
+// WithSamplerConfig 通过jaeger的 jaegerCfg.SamplerConfig 设置采样策略, 默认为全追踪模式; 与 WithSampler 同时设置时以最后设置的为准
+func WithSamplerConfig(samplerConfig *jaegerCfg.SamplerConfig) Option {
+	return func(opts *options) {
+		if samplerConfig != nil {
+			var cfg = *samplerConfig
+			opts.samplerConfig = &cfg
+			opts.newSampler = nil
+		}
+	}
+}
+
+// WithSampler 直接指定jaeger的 jaeger.Sampler; 与 WithSamplerConfig 同时设置时以最后设置的为准
+func WithSampler(sampler jaeger.Sampler) Option {
+	return func(opts *options) {
+		opts.newSampler = func() jaeger.Sampler { return sampler }
+	}
+}
+
+// WithReporterConfig 通过jaeger的 jaegerCfg.ReporterConfig 设置上报方式, 默认通过udp上报到本机的jaeger-agent; 与 WithReporter 及 WithAgentReporter 同时设置时以最后设置的为准
+func WithReporterConfig(reporterConfig *jaegerCfg.ReporterConfig) Option {
+	return func(opts *options) {
+		if reporterConfig != nil {
+			var cfg = *reporterConfig
+			opts.reporterConfig = &cfg
+			opts.reporter = nil
+			opts.agentMaxPacketSize = 0
+		}
+	}
+}
+
+// WithCollectorEndpoint 通过http直连jaeger服务端上报追踪信息, example: WithCollectorEndpoint("http://127.0.0.1:14268/api/traces")
+func WithCollectorEndpoint(endpoint string) Option {
+	return func(opts *options) {
+		opts.reporterConfig.CollectorEndpoint = endpoint
+		opts.reporter = nil
+	}
+}
+
+// WithReporter 直接指定jaeger的 jaeger.Reporter; 与 WithReporterConfig 及 WithCollectorEndpoint 同时设置时以最后设置的为准
+func WithReporter(reporter jaeger.Reporter) Option {
+	return func(opts *options) { opts.reporter = reporter }
+}
+
+// WithLogger 设置tracer内部信息(如上报失败, 拉取采样策略失败等)的输出方式, 默认通过标准库log的默认logger输出
+func WithLogger(logger Logger) Option {
+	return func(opts *options) {
+		if logger != nil {
+			opts.logger = logger
+		}
+	}
+}
+
+// WithTags 设置tracer级别的全局tag, 将记录在该tracer上报的每个trace的process信息中
+func WithTags(tags ...opentracing.Tag) Option {
+	return func(opts *options) { opts.tags = append(opts.tags, tags...) }
+}
+
+// WithGen128Bit 设置是否生成128位的traceID, 默认为64位
+func WithGen128Bit(gen128Bit bool) Option {
+	return func(opts *options) { opts.gen128Bit = gen128Bit }
+}
+
+// WithPoolSpans 设置是否复用span对象以减少内存分配, 开启后不可在span.Finish()之后继续使用该span
+func WithPoolSpans(poolSpans bool) Option {
+	return func(opts *options) { opts.poolSpans = poolSpans }
+}
+
+// WithMaxTagValueLength 设置tag值的最大长度, 超出部分将被截断, 默认为jaeger的默认值
+func WithMaxTagValueLength(maxTagValueLength int) Option {
+	return func(opts *options) { opts.maxTagValueLength = maxTagValueLength }
+}
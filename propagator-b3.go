@@ -0,0 +1,195 @@
+package tracer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/uber/jaeger-client-go"
+)
+
+// B3SingleHeaderName Zipkin B3单头格式的http头名称, 格式为 {TraceId}-{SpanId}-{SamplingState}-{ParentSpanId}
+const B3SingleHeaderName = "b3"
+
+const b3TraceIDHeaderName = "x-b3-traceid"
+const b3SpanIDHeaderName = "x-b3-spanid"
+const b3ParentSpanIDHeaderName = "x-b3-parentspanid"
+const b3SampledHeaderName = "x-b3-sampled"
+const b3FlagsHeaderName = "x-b3-flags"
+
+const b3Sampled = "1"
+const b3NotSampled = "0"
+const b3Debug = "d"
+
+// jaegerFlagSampled, jaegerFlagDebug 对应jaeger SpanContext 的采样及debug标志位
+const jaegerFlagSampled = 0x1
+const jaegerFlagDebug = 0x2
+
+// NewB3Propagator 按Zipkin B3格式(https://github.com/openzipkin/b3-propagation)传递span信息, 支持64位及128位的traceID; singleHeader为true时注入单头格式(b3), 为false时注入多头格式(X-B3-TraceId, X-B3-SpanId等); 提取时两种格式均支持, 同时存在时优先使用单头格式; jaeger无法表示"由下游决定是否采样", 所以未携带采样标志的span视为不采样; 不传递baggage
+func NewB3Propagator(singleHeader bool) Propagator {
+	return b3Propagator{singleHeader: singleHeader}
+}
+
+type b3Propagator struct {
+	singleHeader bool
+}
+
+func (p b3Propagator) Inject(
+	spanCtx jaeger.SpanContext, abstractCarrier interface{},
+) (err error) {
+
+	var writer, ok = abstractCarrier.(opentracing.TextMapWriter)
+	if !ok {
+		err = opentracing.ErrInvalidCarrier
+		return
+	}
+
+	var traceID = formatB3TraceID(spanCtx.TraceID())
+	var spanID = formatB3SpanID(spanCtx.SpanID())
+	var samplingState = b3NotSampled
+	if spanCtx.IsDebug() {
+		samplingState = b3Debug
+	} else if spanCtx.IsSampled() {
+		samplingState = b3Sampled
+	}
+
+	if p.singleHeader {
+		var value = traceID + "-" + spanID + "-" + samplingState
+		if spanCtx.ParentID() != 0 {
+			value += "-" + formatB3SpanID(spanCtx.ParentID())
+		}
+		writer.Set(B3SingleHeaderName, value)
+		return
+	}
+
+	writer.Set(b3TraceIDHeaderName, traceID)
+	writer.Set(b3SpanIDHeaderName, spanID)
+	if spanCtx.ParentID() != 0 {
+		writer.Set(b3ParentSpanIDHeaderName, formatB3SpanID(spanCtx.ParentID()))
+	}
+	if samplingState == b3Debug {
+		// debug隐含了采样, 按规范不再发送X-B3-Sampled
+		writer.Set(b3FlagsHeaderName, b3Sampled)
+	} else {
+		writer.Set(b3SampledHeaderName, samplingState)
+	}
+	return
+}
+
+func (p b3Propagator) Extract(abstractCarrier interface{}) (
+	spanCtx jaeger.SpanContext, err error,
+) {
+
+	var reader, ok = abstractCarrier.(opentracing.TextMapReader)
+	if !ok {
+		err = opentracing.ErrInvalidCarrier
+		return
+	}
+
+	var single, traceID, spanID, parentSpanID, sampled, flags string
+	if err = reader.ForeachKey(func(key, val string) error {
+		switch strings.ToLower(key) {
+		case B3SingleHeaderName:
+			single = val
+		case b3TraceIDHeaderName:
+			traceID = val
+		case b3SpanIDHeaderName:
+			spanID = val
+		case b3ParentSpanIDHeaderName:
+			parentSpanID = val
+		case b3SampledHeaderName:
+			sampled = val
+		case b3FlagsHeaderName:
+			flags = val
+		}
+		return nil
+	}); err != nil {
+		return
+	}
+
+	if single = strings.TrimSpace(single); single != emptyString {
+		var parts = strings.Split(single, "-")
+		if len(parts) < 2 {
+			// 只有采样标志(如"b3: 0")时没有可延续的trace
+			err = opentracing.ErrSpanContextNotFound
+			return
+		}
+		if len(parts) > 4 {
+			err = opentracing.ErrSpanContextCorrupted
+			return
+		}
+		traceID, spanID, sampled, parentSpanID = parts[0], parts[1], emptyString, emptyString
+		if len(parts) > 2 {
+			sampled = parts[2]
+		}
+		if len(parts) > 3 {
+			parentSpanID = parts[3]
+		}
+	} else if traceID == emptyString {
+		err = opentracing.ErrSpanContextNotFound
+		return
+	} else if flags == b3Sampled {
+		sampled = b3Debug
+	}
+	spanCtx, err = newB3SpanContext(traceID, spanID, parentSpanID, sampled)
+	return
+}
+
+func newB3SpanContext(traceID, spanID, parentSpanID, sampled string) (
+	spanCtx jaeger.SpanContext, err error,
+) {
+
+	if (len(traceID) != 16 && len(traceID) != 32) || len(spanID) != 16 ||
+		(parentSpanID != emptyString && len(parentSpanID) != 16) {
+		err = opentracing.ErrSpanContextCorrupted
+		return
+	}
+
+	var tID jaeger.TraceID
+	var sID, pID jaeger.SpanID
+	if tID, err = jaeger.TraceIDFromString(traceID); err != nil {
+		return
+	}
+	if sID, err = jaeger.SpanIDFromString(spanID); err != nil {
+		return
+	}
+	if parentSpanID != emptyString {
+		if pID, err = jaeger.SpanIDFromString(parentSpanID); err != nil {
+			return
+		}
+	}
+	if !tID.IsValid() || sID == 0 {
+		err = opentracing.ErrSpanContextCorrupted
+		return
+	}
+
+	var flags byte
+	switch strings.ToLower(sampled) {
+	case b3Sampled, "true":
+		flags = jaegerFlagSampled
+	case b3Debug:
+		// jaeger.NewSpanContext 无法设置debug标志, 通过 ContextFromString 保留
+		flags = jaegerFlagSampled | jaegerFlagDebug
+	case b3NotSampled, "false", emptyString:
+	default:
+		err = opentracing.ErrSpanContextCorrupted
+		return
+	}
+	spanCtx, err = jaeger.ContextFromString(
+		fmt.Sprintf("%s:%s:%s:%x", tID, sID, pID, flags),
+	)
+	return
+}
+
+// formatB3TraceID 64位的traceID为16位16进制, 128位的为32位16进制, 均不足位补0
+func formatB3TraceID(traceID jaeger.TraceID) string {
+
+	if traceID.High == 0 {
+		return fmt.Sprintf("%016x", traceID.Low)
+	}
+	return fmt.Sprintf("%016x%016x", traceID.High, traceID.Low)
+}
+
+func formatB3SpanID(spanID jaeger.SpanID) string {
+	return fmt.Sprintf("%016x", uint64(spanID))
+}
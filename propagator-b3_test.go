@@ -0,0 +1,268 @@
+package tracer
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/uber/jaeger-client-go"
+	"github.com/valyala/fasthttp"
+)
+
+func TestB3PropagatorExtract(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      map[string]string
+		wantJoined  bool
+		wantTraceID string
+		wantSampled bool
+		wantDebug   bool
+	}{
+		{
+			name:        "single 64 bit sampled",
+			header:      map[string]string{"b3": "a3ce929d0e0e4736-00f067aa0ba902b7-1"},
+			wantJoined:  true,
+			wantTraceID: "a3ce929d0e0e4736",
+			wantSampled: true,
+		},
+		{
+			name:        "single 128 bit with parent",
+			header:      map[string]string{"b3": "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0-05e3ac9a4f6e3b90"},
+			wantJoined:  true,
+			wantTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name:        "single debug",
+			header:      map[string]string{"b3": "a3ce929d0e0e4736-00f067aa0ba902b7-d"},
+			wantJoined:  true,
+			wantTraceID: "a3ce929d0e0e4736",
+			wantSampled: true,
+			wantDebug:   true,
+		},
+		{
+			name: "multi 128 bit sampled",
+			header: map[string]string{
+				"X-B3-TraceId":      "4bf92f3577b34da6a3ce929d0e0e4736",
+				"X-B3-SpanId":       "00f067aa0ba902b7",
+				"X-B3-ParentSpanId": "05e3ac9a4f6e3b90",
+				"X-B3-Sampled":      "1",
+			},
+			wantJoined:  true,
+			wantTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			wantSampled: true,
+		},
+		{
+			name: "multi debug flags",
+			header: map[string]string{
+				"X-B3-TraceId": "a3ce929d0e0e4736",
+				"X-B3-SpanId":  "00f067aa0ba902b7",
+				"X-B3-Flags":   "1",
+			},
+			wantJoined:  true,
+			wantTraceID: "a3ce929d0e0e4736",
+			wantSampled: true,
+			wantDebug:   true,
+		},
+		{
+			name: "single preferred over multi",
+			header: map[string]string{
+				"b3":           "a3ce929d0e0e4736-00f067aa0ba902b7-1",
+				"X-B3-TraceId": "4bf92f3577b34da6a3ce929d0e0e4736",
+				"X-B3-SpanId":  "00f067aa0ba902b7",
+			},
+			wantJoined:  true,
+			wantTraceID: "a3ce929d0e0e4736",
+			wantSampled: true,
+		},
+		{
+			name:   "sampling state only",
+			header: map[string]string{"b3": "0"},
+		},
+		{
+			name:   "invalid trace id length",
+			header: map[string]string{"b3": "a3ce929d0e0e47-00f067aa0ba902b7-1"},
+		},
+		{
+			name:   "invalid sampling state",
+			header: map[string]string{"b3": "a3ce929d0e0e4736-00f067aa0ba902b7-x"},
+		},
+		{
+			name: "multi missing span id",
+			header: map[string]string{
+				"X-B3-TraceId": "a3ce929d0e0e4736",
+				"X-B3-Sampled": "1",
+			},
+		},
+	}
+	var ti, _ = newTestTracer(
+		t, WithPropagator(NewB3Propagator(false)), WithConstSampler(false),
+	)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var header = http.Header{}
+			for k, v := range tt.header {
+				header.Set(k, v)
+			}
+			var child = ti.ChildSpanFromHttpHeader("child", header)
+			defer child.Finish()
+
+			var spanCtx = child.Context().(jaeger.SpanContext)
+			var traceID = spanCtx.TraceID().String()
+			if joined := traceID == strings.TrimLeft(tt.wantTraceID, "0"); joined != tt.wantJoined {
+				t.Fatalf("trace id = %v, joined = %v, want %v", traceID, joined, tt.wantJoined)
+			}
+			if tt.wantJoined && spanCtx.IsSampled() != tt.wantSampled {
+				t.Errorf("sampled = %v, want %v", spanCtx.IsSampled(), tt.wantSampled)
+			}
+			if tt.wantJoined && spanCtx.IsDebug() != tt.wantDebug {
+				t.Errorf("debug = %v, want %v", spanCtx.IsDebug(), tt.wantDebug)
+			}
+		})
+	}
+}
+
+func TestB3PropagatorInject(t *testing.T) {
+	tests := []struct {
+		name         string
+		singleHeader bool
+		gen128Bit    bool
+		sampled      bool
+	}{
+		{name: "single 64 bit sampled", singleHeader: true, sampled: true},
+		{name: "single 128 bit not sampled", singleHeader: true, gen128Bit: true},
+		{name: "multi 64 bit not sampled"},
+		{name: "multi 128 bit sampled", gen128Bit: true, sampled: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ti, _ = newTestTracer(
+				t, WithPropagator(NewB3Propagator(tt.singleHeader)),
+				WithGen128Bit(tt.gen128Bit), WithConstSampler(tt.sampled),
+			)
+			var parent = ti.StartSpan("parent")
+			defer parent.Finish()
+			var span = ti.ChildSpanFromContext(
+				"span", ti.ContextWithSpan(context.Background(), parent),
+			)
+			defer span.Finish()
+			var header = http.Header{}
+			if err := ti.Inject2HttpHeader(span, header); err != nil {
+				t.Fatalf("Inject2HttpHeader() error = %v", err)
+			}
+
+			var spanCtx = span.Context().(jaeger.SpanContext)
+			var traceIDLen, sampled = 16, "0"
+			if tt.gen128Bit {
+				traceIDLen = 32
+			}
+			if tt.sampled {
+				sampled = "1"
+			}
+			var traceID, spanID, gotSampled, parentID string
+			if tt.singleHeader {
+				var parts = strings.Split(header.Get(B3SingleHeaderName), "-")
+				if len(parts) != 4 {
+					t.Fatalf("invalid b3 header %v", header.Get(B3SingleHeaderName))
+				}
+				traceID, spanID, gotSampled, parentID = parts[0], parts[1], parts[2], parts[3]
+			} else {
+				traceID, spanID = header.Get("X-B3-TraceId"), header.Get("X-B3-SpanId")
+				gotSampled, parentID = header.Get("X-B3-Sampled"), header.Get("X-B3-ParentSpanId")
+			}
+			if tID, _ := jaeger.TraceIDFromString(traceID); len(traceID) != traceIDLen || tID != spanCtx.TraceID() {
+				t.Errorf("trace id = %v, want %v", traceID, spanCtx.TraceID())
+			}
+			if spanID != formatB3SpanID(spanCtx.SpanID()) {
+				t.Errorf("span id = %v, want %v", spanID, spanCtx.SpanID())
+			}
+			if parentID != formatB3SpanID(spanCtx.ParentID()) {
+				t.Errorf("parent span id = %v, want %v", parentID, spanCtx.ParentID())
+			}
+			if gotSampled != sampled {
+				t.Errorf("sampled = %v, want %v", gotSampled, sampled)
+			}
+		})
+	}
+}
+
+func TestB3PropagatorDebugRoundTrip(t *testing.T) {
+	tests := []struct {
+		name         string
+		singleHeader bool
+		header       map[string]string
+		wantHeader   string
+		wantValue    string
+	}{
+		{
+			name:         "single",
+			singleHeader: true,
+			header:       map[string]string{"b3": "a3ce929d0e0e4736-00f067aa0ba902b7-d"},
+			wantHeader:   "b3",
+			wantValue:    "d",
+		},
+		{
+			name: "multi",
+			header: map[string]string{
+				"X-B3-TraceId": "a3ce929d0e0e4736",
+				"X-B3-SpanId":  "00f067aa0ba902b7",
+				"X-B3-Flags":   "1",
+			},
+			wantHeader: "X-B3-Flags",
+			wantValue:  "1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ti, _ = newTestTracer(
+				t, WithPropagator(NewB3Propagator(tt.singleHeader)), WithConstSampler(false),
+			)
+			var header = http.Header{}
+			for k, v := range tt.header {
+				header.Set(k, v)
+			}
+			var child = ti.ChildSpanFromHttpHeader("child", header)
+			defer child.Finish()
+
+			var out = http.Header{}
+			if err := ti.Inject2HttpHeader(child, out); err != nil {
+				t.Fatalf("Inject2HttpHeader() error = %v", err)
+			}
+			var got = out.Get(tt.wantHeader)
+			if tt.singleHeader {
+				got = strings.Split(got, "-")[2]
+			}
+			if got != tt.wantValue {
+				t.Errorf("%v = %v, want %v", tt.wantHeader, out.Get(tt.wantHeader), tt.wantValue)
+			}
+			if tt.singleHeader {
+				return
+			}
+			if sampled := out.Get("X-B3-Sampled"); sampled != emptyString {
+				t.Errorf("X-B3-Sampled = %v, want empty", sampled)
+			}
+		})
+	}
+}
+
+func TestB3PropagatorFasthttp(t *testing.T) {
+
+	var ti, _ = newTestTracer(t, WithPropagator(NewB3Propagator(true)))
+	var span = ti.StartSpan("client")
+	defer span.Finish()
+	var req fasthttp.RequestHeader
+	if err := ti.Inject2FasthttpHeader(span, &req); err != nil {
+		t.Fatalf("Inject2FasthttpHeader() error = %v", err)
+	}
+
+	var resp fasthttp.ResponseHeader
+	resp.SetBytesV(B3SingleHeaderName, req.Peek(B3SingleHeaderName))
+	var ctx = ti.CtxWithSpanCtxFromFasthttpHeader(context.Background(), &resp)
+	var child = ti.ChildSpanFromContext("child", ctx)
+	defer child.Finish()
+	var want = span.Context().(jaeger.SpanContext)
+	var got = child.Context().(jaeger.SpanContext)
+	if got.TraceID() != want.TraceID() || got.ParentID() != want.SpanID() {
+		t.Errorf("child = %v, want child of %v", got, want)
+	}
+}
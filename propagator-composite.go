@@ -0,0 +1,79 @@
+package tracer
+
+import (
+	"errors"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/uber/jaeger-client-go"
+)
+
+// WithPropagators 按顺序组合多个 Propagator, 等同于 WithPropagator(NewCompositePropagator(propagators...)), 适用于迁移期间需要同时兼容多种http头格式的服务, example: WithPropagators(NewJaegerPropagator(nil), NewW3CPropagator(), NewB3Propagator(false))
+func WithPropagators(propagators ...Propagator) Option {
+	return WithPropagator(NewCompositePropagator(propagators...))
+}
+
+// NewCompositePropagator 按顺序组合多个 Propagator; 提取时依次尝试, 使用第一个成功提取到有效span信息的结果, 均未提取到时返回第一个非 opentracing.ErrSpanContextNotFound 的错误; 注入时依次写入所有格式, 返回所有注入失败的错误; nil会被忽略
+func NewCompositePropagator(propagators ...Propagator) Propagator {
+
+	var cp = compositePropagator{
+		propagators: make([]Propagator, 0, len(propagators)),
+	}
+	for _, propagator := range propagators {
+		if propagator != nil {
+			cp.propagators = append(cp.propagators, propagator)
+		}
+	}
+	return cp
+}
+
+type compositePropagator struct {
+	propagators []Propagator
+}
+
+func (cp compositePropagator) Inject(
+	spanCtx jaeger.SpanContext, abstractCarrier interface{},
+) (err error) {
+
+	var errs []error
+	for _, propagator := range cp.propagators {
+		if e := propagator.Inject(spanCtx, abstractCarrier); e != nil {
+			errs = append(errs, e)
+		}
+	}
+	err = errors.Join(errs...)
+	return
+}
+
+func (cp compositePropagator) Extract(abstractCarrier interface{}) (
+	spanCtx jaeger.SpanContext, err error,
+) {
+
+	var fallback jaeger.SpanContext
+	var hasFallback bool
+	for _, propagator := range cp.propagators {
+		var sc, e = propagator.Extract(abstractCarrier)
+		if e == nil {
+			if sc.IsValid() {
+				spanCtx = sc
+				err = nil
+				return
+			}
+			// 如jaeger格式只携带jaeger-debug-id或baggage时, span信息无效但仍需传递, 其余格式均无有效span信息时使用
+			if !hasFallback {
+				fallback, hasFallback = sc, true
+			}
+			continue
+		}
+		if err == nil || err == opentracing.ErrSpanContextNotFound {
+			err = e
+		}
+	}
+	if hasFallback {
+		spanCtx, err = fallback, nil
+		return
+	}
+	if err == nil {
+		err = opentracing.ErrSpanContextNotFound
+	}
+	return
+}
@@ -0,0 +1,122 @@
+package tracer
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/uber/jaeger-client-go"
+	"github.com/valyala/fasthttp"
+)
+
+func TestCompositePropagatorExtract(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      map[string]string
+		wantJoined  bool
+		wantTraceID string
+	}{
+		{
+			name:        "ot-mw",
+			header:      map[string]string{"ot-mw-trace-id": "a3ce929d0e0e4736:00f067aa0ba902b7:0:1"},
+			wantJoined:  true,
+			wantTraceID: "a3ce929d0e0e4736",
+		},
+		{
+			name:        "uber",
+			header:      map[string]string{"uber-trace-id": "a3ce929d0e0e4736:00f067aa0ba902b7:0:1"},
+			wantJoined:  true,
+			wantTraceID: "a3ce929d0e0e4736",
+		},
+		{
+			name:        "w3c",
+			header:      map[string]string{W3CTraceParentHeaderName: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+			wantJoined:  true,
+			wantTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name:        "b3",
+			header:      map[string]string{B3SingleHeaderName: "a3ce929d0e0e4736-00f067aa0ba902b7-1"},
+			wantJoined:  true,
+			wantTraceID: "a3ce929d0e0e4736",
+		},
+		{
+			name: "first valid wins",
+			header: map[string]string{
+				"uber-trace-id":          "1111111111111111:00f067aa0ba902b7:0:1",
+				W3CTraceParentHeaderName: "00-22222222222222222222222222222222-00f067aa0ba902b7-01",
+			},
+			wantJoined:  true,
+			wantTraceID: "1111111111111111",
+		},
+		{
+			name: "corrupted skipped",
+			header: map[string]string{
+				"ot-mw-trace-id":   "invalid",
+				B3SingleHeaderName: "a3ce929d0e0e4736-00f067aa0ba902b7-1",
+			},
+			wantJoined:  true,
+			wantTraceID: "a3ce929d0e0e4736",
+		},
+		{
+			name: "none",
+		},
+	}
+	var ti, _ = newTestTracer(t, WithPropagators(
+		NewJaegerPropagator(OtMwHeadersConfig()),
+		NewJaegerPropagator(UberHeadersConfig()),
+		NewW3CPropagator(),
+		NewB3Propagator(true),
+	))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var header = http.Header{}
+			for k, v := range tt.header {
+				header.Set(k, v)
+			}
+			var child = ti.ChildSpanFromHttpHeader("child", header)
+			defer child.Finish()
+
+			var traceID = child.Context().(jaeger.SpanContext).TraceID().String()
+			if joined := traceID == tt.wantTraceID; joined != tt.wantJoined {
+				t.Errorf("trace id = %v, joined = %v, want %v", traceID, joined, tt.wantJoined)
+			}
+		})
+	}
+}
+
+func TestCompositePropagatorExtractError(t *testing.T) {
+
+	var cp = NewCompositePropagator(NewW3CPropagator(), nil, NewB3Propagator(true))
+	var header = http.Header{}
+	if _, err := cp.Extract(opentracing.HTTPHeadersCarrier(header)); err != opentracing.ErrSpanContextNotFound {
+		t.Errorf("Extract() error = %v, want %v", err, opentracing.ErrSpanContextNotFound)
+	}
+	header.Set(B3SingleHeaderName, "invalid-invalid")
+	if _, err := cp.Extract(opentracing.HTTPHeadersCarrier(header)); err != opentracing.ErrSpanContextCorrupted {
+		t.Errorf("Extract() error = %v, want %v", err, opentracing.ErrSpanContextCorrupted)
+	}
+}
+
+func TestCompositePropagatorInject(t *testing.T) {
+
+	var ti, _ = newTestTracer(t, WithPropagators(
+		NewJaegerPropagator(nil), NewW3CPropagator(), NewB3Propagator(false),
+	))
+	var span = ti.StartSpan("client")
+	defer span.Finish()
+	var header fasthttp.RequestHeader
+	if err := ti.Inject2FasthttpHeaderByCtx(
+		ti.ContextWithSpan(context.Background(), span), &header,
+	); err != nil {
+		t.Fatalf("Inject2FasthttpHeaderByCtx() error = %v", err)
+	}
+	for _, key := range []string{
+		"uber-trace-id", W3CTraceParentHeaderName, "X-B3-TraceId", "X-B3-SpanId",
+	} {
+		if len(header.Peek(key)) == 0 {
+			t.Errorf("header %v not injected", key)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package tracer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/uber/jaeger-client-go"
+)
+
+// W3CTraceParentHeaderName W3C Trace Context规范中传递trace信息的http头名称
+const W3CTraceParentHeaderName = "traceparent"
+
+// W3CTraceStateHeaderName W3C Trace Context规范中传递各厂商自定义信息的http头名称
+const W3CTraceStateHeaderName = "tracestate"
+
+const w3cTraceParentVersion = "00"
+const w3cTraceParentVersionInvalid = "ff"
+const w3cTraceParentSampledFlag = 0x01
+
+// w3cTraceStateMaxMembers tracestate最多包含的厂商(list-member)数量
+const w3cTraceStateMaxMembers = 32
+
+// w3cTraceStateKey tracestate在 jaeger.SpanContext 中的存储key, 存储于同一trace的所有span共享的采样状态中, 在本服务内随trace传递而不会像baggage一样被其他格式的 Propagator 注入到http头中
+type w3cTraceStateKey struct{}
+
+var emptyW3CTraceState = func() interface{} { return emptyString }
+
+// NewW3CPropagator 按W3C Trace Context规范(https://www.w3.org/TR/trace-context/)通过traceparent及tracestate头传递span信息, 可与浏览器, api网关及OpenTelemetry的服务互通; 传递采样标志, tracestate原样向下游传递; 不传递baggage
+func NewW3CPropagator() Propagator { return w3cPropagator{} }
+
+type w3cPropagator struct{}
+
+func (p w3cPropagator) Inject(
+	spanCtx jaeger.SpanContext, abstractCarrier interface{},
+) (err error) {
+
+	var writer, ok = abstractCarrier.(opentracing.TextMapWriter)
+	if !ok {
+		err = opentracing.ErrInvalidCarrier
+		return
+	}
+
+	var flags byte
+	if spanCtx.IsSampled() {
+		flags = w3cTraceParentSampledFlag
+	}
+	var traceID = spanCtx.TraceID()
+	writer.Set(W3CTraceParentHeaderName, fmt.Sprintf(
+		"%s-%016x%016x-%016x-%02x", w3cTraceParentVersion,
+		traceID.High, traceID.Low, uint64(spanCtx.SpanID()), flags,
+	))
+	if traceState, _ := spanCtx.ExtendedSamplingState(
+		w3cTraceStateKey{}, emptyW3CTraceState,
+	).(string); traceState != emptyString {
+		writer.Set(W3CTraceStateHeaderName, traceState)
+	}
+	return
+}
+
+func (p w3cPropagator) Extract(abstractCarrier interface{}) (
+	spanCtx jaeger.SpanContext, err error,
+) {
+
+	var reader, ok = abstractCarrier.(opentracing.TextMapReader)
+	if !ok {
+		err = opentracing.ErrInvalidCarrier
+		return
+	}
+
+	var traceParent string
+	var traceStates []string
+	if err = reader.ForeachKey(func(key, val string) error {
+		switch strings.ToLower(key) {
+		case W3CTraceParentHeaderName:
+			traceParent = val
+		case W3CTraceStateHeaderName:
+			traceStates = append(traceStates, val)
+		}
+		return nil
+	}); err != nil {
+		return
+	}
+	if traceParent == emptyString {
+		err = opentracing.ErrSpanContextNotFound
+		return
+	}
+
+	var traceID jaeger.TraceID
+	var spanID jaeger.SpanID
+	var sampled bool
+	if traceID, spanID, sampled, err = parseW3CTraceParent(
+		traceParent,
+	); err != nil {
+		return
+	}
+	spanCtx = jaeger.NewSpanContext(traceID, spanID, 0, sampled, nil)
+	if traceState := normalizeW3CTraceState(
+		traceStates,
+	); traceState != emptyString {
+		spanCtx.ExtendedSamplingState(
+			w3cTraceStateKey{}, func() interface{} { return traceState },
+		)
+	}
+	return
+}
+
+// parseW3CTraceParent 解析traceparent, 格式为 version-traceID(32位16进制)-parentID(16位16进制)-flags(2位16进制), 高于00的版本只解析前4部分
+func parseW3CTraceParent(traceParent string) (
+	traceID jaeger.TraceID, spanID jaeger.SpanID, sampled bool, err error,
+) {
+
+	var parts = strings.Split(strings.TrimSpace(traceParent), "-")
+	if len(parts) < 4 || !isLowerHex(parts[0], 2) ||
+		parts[0] == w3cTraceParentVersionInvalid ||
+		(parts[0] == w3cTraceParentVersion && len(parts) != 4) ||
+		!isLowerHex(parts[1], 32) || !isLowerHex(parts[2], 16) ||
+		!isLowerHex(parts[3], 2) {
+		err = opentracing.ErrSpanContextCorrupted
+		return
+	}
+
+	var flags uint64
+	if traceID, err = jaeger.TraceIDFromString(parts[1]); err != nil {
+		return
+	}
+	if spanID, err = jaeger.SpanIDFromString(parts[2]); err != nil {
+		return
+	}
+	if flags, err = strconv.ParseUint(parts[3], 16, 8); err != nil {
+		return
+	}
+	if !traceID.IsValid() || spanID == 0 {
+		err = opentracing.ErrSpanContextCorrupted
+		return
+	}
+	sampled = flags&w3cTraceParentSampledFlag != 0
+	return
+}
+
+// normalizeW3CTraceState 合并多个tracestate头, 去除空的list-member及多余的空白, 最多保留 w3cTraceStateMaxMembers 个list-member
+func normalizeW3CTraceState(traceStates []string) (traceState string) {
+
+	var members = make([]string, 0, len(traceStates))
+	for _, ts := range traceStates {
+		for _, member := range strings.Split(ts, ",") {
+			if member = strings.TrimSpace(member); member != emptyString {
+				members = append(members, member)
+			}
+		}
+	}
+	if len(members) > w3cTraceStateMaxMembers {
+		members = members[:w3cTraceStateMaxMembers]
+	}
+	traceState = strings.Join(members, ",")
+	return
+}
+
+// isLowerHex 判断s是否为长度为length的小写16进制字符串
+func isLowerHex(s string, length int) bool {
+
+	if len(s) != length {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if (s[i] < '0' || s[i] > '9') && (s[i] < 'a' || s[i] > 'f') {
+			return false
+		}
+	}
+	return true
+}
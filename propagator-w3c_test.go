@@ -0,0 +1,132 @@
+package tracer
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/uber/jaeger-client-go"
+	"github.com/valyala/fasthttp"
+)
+
+func TestW3CPropagatorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name           string
+		traceParent    string
+		traceState     []string
+		wantJoined     bool
+		wantTraceID    string
+		wantFlags      string
+		wantTraceState string
+	}{
+		{
+			name:           "sampled with tracestate",
+			traceParent:    "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			traceState:     []string{"rojo=00f067aa0ba902b7, congo=t61rcWkgMzE"},
+			wantJoined:     true,
+			wantTraceID:    "4bf92f3577b34da6a3ce929d0e0e4736",
+			wantFlags:      "01",
+			wantTraceState: "rojo=00f067aa0ba902b7,congo=t61rcWkgMzE",
+		},
+		{
+			name:        "not sampled",
+			traceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			wantJoined:  true,
+			wantTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			wantFlags:   "00",
+		},
+		{
+			name:           "multiple tracestate headers",
+			traceParent:    "00-0000000000000000a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			traceState:     []string{"rojo=1", "congo=2"},
+			wantJoined:     true,
+			wantTraceID:    "0000000000000000a3ce929d0e0e4736",
+			wantFlags:      "01",
+			wantTraceState: "rojo=1,congo=2",
+		},
+		{
+			name:        "future version with extra fields",
+			traceParent: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+			wantJoined:  true,
+			wantTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			wantFlags:   "01",
+		},
+		{
+			name:        "invalid version ff",
+			traceParent: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		},
+		{
+			name:        "all zero trace id",
+			traceParent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		},
+		{
+			name:        "upper case hex",
+			traceParent: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		},
+		{
+			name:        "version 00 with extra fields",
+			traceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		},
+	}
+	var ti, _ = newTestTracer(
+		t, WithPropagator(NewW3CPropagator()), WithConstSampler(false),
+	)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var header = http.Header{}
+			header.Set(W3CTraceParentHeaderName, tt.traceParent)
+			for _, ts := range tt.traceState {
+				header.Add(W3CTraceStateHeaderName, ts)
+			}
+			var child = ti.ChildSpanFromHttpHeader("child", header)
+			defer child.Finish()
+
+			var downstream = http.Header{}
+			if err := ti.Inject2HttpHeader(child, downstream); err != nil {
+				t.Fatalf("Inject2HttpHeader() error = %v", err)
+			}
+			var parts = strings.Split(downstream.Get(W3CTraceParentHeaderName), "-")
+			if len(parts) != 4 || parts[0] != "00" {
+				t.Fatalf("invalid traceparent %v", downstream.Get(W3CTraceParentHeaderName))
+			}
+			if joined := parts[1] == tt.wantTraceID; joined != tt.wantJoined {
+				t.Errorf("trace id = %v, joined = %v, want %v", parts[1], joined, tt.wantJoined)
+			}
+			if !tt.wantJoined {
+				return
+			}
+			if parts[2] == strings.Split(tt.traceParent, "-")[2] {
+				t.Errorf("span id not changed for child span")
+			}
+			if parts[3] != tt.wantFlags {
+				t.Errorf("flags = %v, want %v", parts[3], tt.wantFlags)
+			}
+			if got := downstream.Get(W3CTraceStateHeaderName); got != tt.wantTraceState {
+				t.Errorf("tracestate = %v, want %v", got, tt.wantTraceState)
+			}
+		})
+	}
+}
+
+func TestW3CPropagatorFasthttp(t *testing.T) {
+
+	var ti, _ = newTestTracer(t, WithPropagator(NewW3CPropagator()))
+	var span = ti.StartSpan("client")
+	defer span.Finish()
+	var req fasthttp.RequestHeader
+	if err := ti.Inject2FasthttpHeader(span, &req); err != nil {
+		t.Fatalf("Inject2FasthttpHeader() error = %v", err)
+	}
+
+	var resp fasthttp.ResponseHeader
+	resp.SetBytesV(W3CTraceParentHeaderName, req.Peek(W3CTraceParentHeaderName))
+	var ctx = ti.CtxWithSpanCtxFromFasthttpHeader(context.Background(), &resp)
+	var child = ti.ChildSpanFromContext("child", ctx)
+	defer child.Finish()
+	var want = span.Context().(jaeger.SpanContext)
+	var got = child.Context().(jaeger.SpanContext)
+	if got.TraceID() != want.TraceID() || got.ParentID() != want.SpanID() {
+		t.Errorf("child = %v, want child of %v", got, want)
+	}
+}
@@ -0,0 +1,57 @@
+package tracer
+
+import (
+	"strings"
+
+	"github.com/uber/jaeger-client-go"
+)
+
+// Propagator 负责span信息在carrier中的注入与提取, 设置后将同时用于 opentracing.HTTPHeaders 格式(http.Header)及fasthttp头
+type Propagator interface {
+	jaeger.Injector
+	jaeger.Extractor
+}
+
+// WithPropagator 设置span信息在http头中的注入与提取方式, 默认使用ot-mw-*系列http头; 可选 NewJaegerPropagator, NewW3CPropagator, NewB3Propagator, 需同时支持多种格式时使用 WithPropagators
+func WithPropagator(propagator Propagator) Option {
+	return func(opts *options) {
+		if propagator != nil {
+			opts.propagator = propagator
+		}
+	}
+}
+
+// WithHeadersConfig 设置传递span信息使用的http头名称, 等同于 WithPropagator(NewJaegerPropagator(headersConfig)); 如需与使用默认配置的jaeger客户端互通, 可使用 WithHeadersConfig(UberHeadersConfig())
+func WithHeadersConfig(headersConfig *jaeger.HeadersConfig) Option {
+	return WithPropagator(NewJaegerPropagator(headersConfig))
+}
+
+// OtMwHeadersConfig 本包默认使用的ot-mw-*系列http头名称, 每次调用返回新的实例, 可在修改后通过 WithHeadersConfig 设置
+func OtMwHeadersConfig() (headersConfig *jaeger.HeadersConfig) {
+
+	var cfg = *pJaegerHeaderConfig
+	headersConfig = &cfg
+	return
+}
+
+// UberHeadersConfig jaeger客户端默认使用的http头名称(uber-trace-id, uberctx-, jaeger-debug-id, jaeger-baggage), 每次调用返回新的实例
+func UberHeadersConfig() (headersConfig *jaeger.HeadersConfig) {
+
+	headersConfig = (&jaeger.HeadersConfig{}).ApplyDefaults()
+	return
+}
+
+// NewJaegerPropagator 按jaeger的格式通过headersConfig指定的http头传递span信息, headersConfig中未设置的名称使用jaeger的默认值; jaeger解析前会将http头的key转换成小写, 所以名称统一转换为小写
+func NewJaegerPropagator(headersConfig *jaeger.HeadersConfig) Propagator {
+
+	var cfg jaeger.HeadersConfig
+	if headersConfig != nil {
+		cfg = *headersConfig
+	}
+	cfg.ApplyDefaults()
+	cfg.JaegerDebugHeader = strings.ToLower(cfg.JaegerDebugHeader)
+	cfg.JaegerBaggageHeader = strings.ToLower(cfg.JaegerBaggageHeader)
+	cfg.TraceContextHeaderName = strings.ToLower(cfg.TraceContextHeaderName)
+	cfg.TraceBaggageHeaderPrefix = strings.ToLower(cfg.TraceBaggageHeaderPrefix)
+	return jaeger.NewHTTPHeaderPropagator(&cfg, *pJaegerNullMetrics)
+}
@@ -0,0 +1,72 @@
+package tracer
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/uber/jaeger-client-go"
+)
+
+func TestWithHeadersConfig(t *testing.T) {
+
+	// 使用jaeger默认配置的客户端, 模拟其他团队的服务
+	var stockTracer, stockCloser = jaeger.NewTracer(
+		"stock-jaeger", jaeger.NewConstSampler(true), jaeger.NewNullReporter(),
+	)
+	defer stockCloser.Close()
+
+	tests := []struct {
+		name       string
+		opts       []Option
+		wantHeader string
+		joinsStock bool
+	}{
+		{
+			name:       "default ot-mw headers",
+			wantHeader: OtMwTraceContextHeaderName,
+		},
+		{
+			name:       "stock uber headers",
+			opts:       []Option{WithHeadersConfig(UberHeadersConfig())},
+			wantHeader: jaeger.TraceContextHeaderName,
+			joinsStock: true,
+		},
+		{
+			name: "custom headers",
+			opts: []Option{WithHeadersConfig(&jaeger.HeadersConfig{
+				TraceContextHeaderName: "X-My-Trace-Id",
+			})},
+			wantHeader: "x-my-trace-id",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ti, _ = newTestTracer(t, tt.opts...)
+			var span = ti.StartSpan("inject")
+			defer span.Finish()
+			var header = http.Header{}
+			if err := ti.Inject2HttpHeader(span, header); err != nil {
+				t.Fatalf("Inject2HttpHeader() error = %v", err)
+			}
+			if header.Get(tt.wantHeader) == "" {
+				t.Errorf("header %v not injected, got %v", tt.wantHeader, header)
+			}
+
+			var stockSpan = stockTracer.StartSpan("stock")
+			defer stockSpan.Finish()
+			header = http.Header{}
+			stockTracer.Inject(
+				stockSpan.Context(), opentracing.HTTPHeaders,
+				opentracing.HTTPHeadersCarrier(header),
+			)
+			var child = ti.ChildSpanFromHttpHeader("child", header)
+			defer child.Finish()
+			var joined = child.Context().(jaeger.SpanContext).TraceID() ==
+				stockSpan.Context().(jaeger.SpanContext).TraceID()
+			if joined != tt.joinsStock {
+				t.Errorf("joined stock jaeger trace = %v, want %v", joined, tt.joinsStock)
+			}
+		})
+	}
+}
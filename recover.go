@@ -0,0 +1,34 @@
+package tracer
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	opentracingLog "github.com/opentracing/opentracing-go/log"
+)
+
+// WithRecoverPanic 设置 HttpMiddleWare 及 FasthttpMiddleWare 捕获到handler的panic后的处理方式; 无论是否设置, 都会将panic的值及堆栈记录到span中并结束span, 默认在此之后继续抛出panic, recoverPanic为true时不再抛出, 而是响应500(已写入响应头时保持原响应); http.ErrAbortHandler 用于主动中断请求, 始终继续抛出
+func WithRecoverPanic(recoverPanic bool) Option {
+	return func(opts *options) { opts.recoverPanic = recoverPanic }
+}
+
+// logPanicToSpan 按opentracing的语义约定将panic记录到span中, 设置 error=true, 并以log的形式记录panic的类型, 值及堆栈
+func logPanicToSpan(span opentracing.Span, v interface{}) {
+
+	ext.Error.Set(span, true)
+	span.LogFields(
+		opentracingLog.String("event", "error"),
+		opentracingLog.String("error.kind", "panic"),
+		opentracingLog.String("error.object", fmt.Sprintf("%T", v)),
+		opentracingLog.String("message", fmt.Sprint(v)),
+		opentracingLog.String("stack", string(debug.Stack())),
+	)
+}
+
+// shouldRepanic 根据配置判断捕获到的panic是否需要继续抛出
+func (ti *tracerImpl) shouldRepanic(v interface{}) bool {
+	return !ti.recoverPanic || v == http.ErrAbortHandler
+}
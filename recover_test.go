@@ -0,0 +1,83 @@
+package tracer
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/uber/jaeger-client-go"
+	"github.com/valyala/fasthttp"
+)
+
+func TestMiddleWareRecoverPanic(t *testing.T) {
+	tests := []struct {
+		name         string
+		recoverPanic bool
+		fasthttp     bool
+		panicValue   interface{}
+		wantRepanic  bool
+	}{
+		{name: "http repanic", panicValue: "boom", wantRepanic: true},
+		{name: "http recover", recoverPanic: true, panicValue: "boom"},
+		{
+			name: "http abort handler", recoverPanic: true,
+			panicValue: http.ErrAbortHandler, wantRepanic: true,
+		},
+		{name: "fasthttp repanic", fasthttp: true, panicValue: "boom", wantRepanic: true},
+		{name: "fasthttp recover", fasthttp: true, recoverPanic: true, panicValue: "boom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ti, reporter = newTestTracer(t, WithRecoverPanic(tt.recoverPanic))
+			var statusCode int
+			var recovered = func() (v interface{}) {
+				defer func() { v = recover() }()
+				if tt.fasthttp {
+					var ctx fasthttp.RequestCtx
+					ctx.Request.SetRequestURI("/panic")
+					ti.FasthttpMiddleWare(func(ctx *fasthttp.RequestCtx) {
+						ctx.SetBodyString("partial")
+						panic(tt.panicValue)
+					})(&ctx)
+					statusCode = ctx.Response.StatusCode()
+				} else {
+					var w = httptest.NewRecorder()
+					ti.HttpMiddleWare(http.HandlerFunc(
+						func(w http.ResponseWriter, r *http.Request) { panic(tt.panicValue) },
+					)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+					statusCode = w.Code
+				}
+				return
+			}()
+
+			if (recovered != nil) != tt.wantRepanic {
+				t.Fatalf("recovered = %v, wantRepanic %v", recovered, tt.wantRepanic)
+			}
+			if !tt.wantRepanic && statusCode != http.StatusInternalServerError {
+				t.Errorf("status code = %v, want 500", statusCode)
+			}
+			if got := reporter.SpansSubmitted(); got != 1 {
+				t.Fatalf("SpansSubmitted() = %v, want 1", got)
+			}
+			var span = reporter.GetSpans()[0].(*jaeger.Span)
+			if span.Tags()["error"] != true {
+				t.Errorf("Tags() = %v, want error=true", span.Tags())
+			}
+			if !tt.wantRepanic && span.Tags()["http.status_code"] != uint16(500) {
+				t.Errorf("Tags() = %v, want http.status_code=500", span.Tags())
+			}
+			var fields = map[string]string{}
+			for _, log := range span.Logs() {
+				for _, field := range log.Fields {
+					fields[field.Key()] = field.Value().(string)
+				}
+			}
+			if fields["message"] != fmt.Sprint(tt.panicValue) ||
+				!strings.Contains(fields["stack"], "recover_test.go") {
+				t.Errorf("Logs() = %v", fields)
+			}
+		})
+	}
+}
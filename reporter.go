@@ -0,0 +1,49 @@
+package tracer
+
+import (
+	"github.com/uber/jaeger-client-go"
+	"github.com/uber/jaeger-client-go/utils"
+)
+
+// WithAgentReporter 通过udp将追踪信息上报到jaeger-agent(agentHostPort, 如"127.0.0.1:6831"), 替代通过http直连jaeger服务端上报; maxPacketSize为单个udp包的最大字节数, queueSize为等待上报的span队列长度, <=0时均使用jaeger的默认值; 与 WithCollectorEndpoint 同时设置时以最后设置的为准
+func WithAgentReporter(agentHostPort string, maxPacketSize, queueSize int) Option {
+	return func(opts *options) {
+		opts.reporterConfig.CollectorEndpoint = emptyString
+		opts.reporterConfig.LocalAgentHostPort = agentHostPort
+		opts.reporterConfig.QueueSize = queueSize
+		opts.agentMaxPacketSize = maxPacketSize
+		opts.reporter = nil
+	}
+}
+
+// newAgentReporter 根据reporterConfig创建udp上报的 jaeger.Reporter, jaegerCfg.ReporterConfig 无法设置udp包的大小, 所以需要自行创建
+func newAgentReporter(opts *options) (reporter jaeger.Reporter, err error) {
+
+	var rc = opts.reporterConfig
+	var transport jaeger.Transport
+	if transport, err = jaeger.NewUDPTransportWithParams(
+		jaeger.UDPTransportParams{
+			AgentClientUDPParams: utils.AgentClientUDPParams{
+				HostPort:                   rc.LocalAgentHostPort,
+				MaxPacketSize:              opts.agentMaxPacketSize,
+				Logger:                     opts.logger,
+				DisableAttemptReconnecting: rc.DisableAttemptReconnecting,
+				AttemptReconnectInterval:   rc.AttemptReconnectInterval,
+			},
+		},
+	); err != nil {
+		return
+	}
+	reporter = jaeger.NewRemoteReporter(
+		transport,
+		jaeger.ReporterOptions.QueueSize(rc.QueueSize),
+		jaeger.ReporterOptions.BufferFlushInterval(rc.BufferFlushInterval),
+		jaeger.ReporterOptions.Logger(opts.logger),
+	)
+	if rc.LogSpans {
+		reporter = jaeger.NewCompositeReporter(
+			jaeger.NewLoggingReporter(opts.logger), reporter,
+		)
+	}
+	return
+}
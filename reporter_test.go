@@ -0,0 +1,42 @@
+package tracer
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestWithAgentReporter(t *testing.T) {
+
+	var conn, err = net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket() error = %v", err)
+	}
+	defer conn.Close()
+
+	var ti Tracer
+	if ti, err = NewTracer(
+		"tracer-test", WithAgentReporter(conn.LocalAddr().String(), 1500, 10),
+	); err != nil {
+		t.Fatalf("NewTracer() error = %v", err)
+	}
+	ti.StartSpan("udp-reporter-op").Finish()
+	// Close时会将队列中的span全部上报
+	if err = ti.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	var buf = make([]byte, 65535)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var n int
+	if n, _, err = conn.ReadFrom(buf); err != nil {
+		t.Fatalf("ReadFrom() error = %v", err)
+	}
+	if n > 1500 {
+		t.Errorf("packet size = %v, want <= 1500", n)
+	}
+	if !bytes.Contains(buf[:n], []byte("udp-reporter-op")) {
+		t.Errorf("packet does not contain the reported span")
+	}
+}
@@ -0,0 +1,255 @@
+package tracer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Response RequestBuilder.Do 的返回结果
+type Response struct {
+	// Ctx 带有下游服务返回的span信息的ctx, 下游未返回时为发起请求时的ctx
+	Ctx context.Context
+	// StatusCode http状态码
+	StatusCode int
+	// Header 响应头
+	Header http.Header
+	// Body 响应体, 是复制后的内容, 可在请求结束后继续使用
+	Body []byte
+}
+
+// MultipartFile RequestBuilder.Multipart 上传的文件, Content在 RequestBuilder.Multipart 调用时即被读取
+type MultipartFile struct {
+	// FieldName 表单字段名称
+	FieldName string
+	// FileName 文件名
+	FileName string
+	// Content 文件内容
+	Content io.Reader
+}
+
+// RequestBuilder 通过链式调用构造并发送带有追踪信息的fasthttp请求, 由 Tracer.Request 创建; 构造过程中的错误(如json序列化失败)将在 Do 时返回, 此时不会发送请求; JSON, Form, Multipart 及 Body 设置的请求体以最后设置的为准, example: tracer.Request(ctx).Method(http.MethodPatch).URL("http://127.0.0.1:8080/users/1").Query("fields", "name", "age").Header("Accept", "application/json").JSON(data).Do()
+type RequestBuilder struct {
+	ti          *tracerImpl
+	ctx         context.Context
+	method      string
+	url         string
+	query       url.Values
+	header      http.Header
+	cookies     map[string]string
+	body        []byte
+	contentType string
+	cbs         []FasthttpRespCallback
+	err         error
+
+	// minStatus, maxStatus 通过 ExpectStatus 设置的成功状态码范围, 均为0时不校验
+	minStatus, maxStatus int
+}
+
+func (ti *tracerImpl) Request(ctx context.Context) (builder *RequestBuilder) {
+
+	builder = &RequestBuilder{
+		ti:     ti,
+		ctx:    ctx,
+		method: http.MethodGet,
+		query:  url.Values{},
+		header: http.Header{},
+	}
+	return
+}
+
+// Method 设置请求方法, 默认为GET, example: Method(http.MethodPatch)
+func (rb *RequestBuilder) Method(method string) *RequestBuilder {
+
+	rb.method = method
+	return rb
+}
+
+// URL 设置请求的url, url中可带有查询参数, 也是client端span的操作名称
+func (rb *RequestBuilder) URL(url string) *RequestBuilder {
+
+	rb.url = url
+	return rb
+}
+
+// Query 追加url的查询参数, 同一个key可设置多个值
+func (rb *RequestBuilder) Query(key string, values ...string) *RequestBuilder {
+
+	for _, value := range values {
+		rb.query.Add(key, value)
+	}
+	return rb
+}
+
+// Header 追加请求头, 同一个key可设置多个值
+func (rb *RequestBuilder) Header(key string, values ...string) *RequestBuilder {
+
+	for _, value := range values {
+		rb.header.Add(key, value)
+	}
+	return rb
+}
+
+// Cookie 设置cookie
+func (rb *RequestBuilder) Cookie(key, value string) *RequestBuilder {
+
+	if rb.cookies == nil {
+		rb.cookies = make(map[string]string)
+	}
+	rb.cookies[key] = value
+	return rb
+}
+
+// Body 以原始字节设置请求体, contentType为空时不设置Content-Type
+func (rb *RequestBuilder) Body(body []byte, contentType string) *RequestBuilder {
+
+	rb.body, rb.contentType = body, contentType
+	return rb
+}
+
+// JSON 将data序列化为json作为请求体, Content-Type为application/json
+func (rb *RequestBuilder) JSON(data interface{}) *RequestBuilder {
+
+	var body, err = jsonSerializer.Marshal(data)
+	if err != nil {
+		rb.setErr(err)
+		return rb
+	}
+	return rb.Body(body, "application/json")
+}
+
+// Form 将values编码为application/x-www-form-urlencoded格式的请求体
+func (rb *RequestBuilder) Form(values url.Values) *RequestBuilder {
+	return rb.Body(
+		[]byte(values.Encode()), "application/x-www-form-urlencoded",
+	)
+}
+
+// Multipart 将fields及files编码为multipart/form-data格式的请求体, 用于上传文件
+func (rb *RequestBuilder) Multipart(
+	fields url.Values, files ...MultipartFile,
+) *RequestBuilder {
+
+	var body bytes.Buffer
+	var writer = multipart.NewWriter(&body)
+	var err error
+	for key, values := range fields {
+		for _, value := range values {
+			if err = writer.WriteField(key, value); err != nil {
+				rb.setErr(err)
+				return rb
+			}
+		}
+	}
+	for _, file := range files {
+		var part io.Writer
+		if part, err = writer.CreateFormFile(
+			file.FieldName, file.FileName,
+		); err != nil {
+			rb.setErr(err)
+			return rb
+		}
+		if _, err = io.Copy(part, file.Content); err != nil {
+			rb.setErr(err)
+			return rb
+		}
+	}
+	if err = writer.Close(); err != nil {
+		rb.setErr(err)
+		return rb
+	}
+	return rb.Body(body.Bytes(), writer.FormDataContentType())
+}
+
+// Callback 设置收到响应后的回调, 与 GetFasthttp 等方法的cbs参数相同
+func (rb *RequestBuilder) Callback(cbs ...FasthttpRespCallback) *RequestBuilder {
+
+	rb.cbs = append(rb.cbs, cbs...)
+	return rb
+}
+
+// ExpectStatus 设置成功的状态码范围[minStatus, maxStatus], 状态码不在该范围内时 Do 返回 *HTTPError 并将span标记为 error=true; 默认不校验, DoJSON 未设置时为[200, 299]; ExpectStatus(0, 0) 表示不校验(DoJSON 使用默认范围), minStatus大于maxStatus时 Do 返回错误
+func (rb *RequestBuilder) ExpectStatus(minStatus, maxStatus int) *RequestBuilder {
+
+	if minStatus > maxStatus {
+		rb.setErr(fmt.Errorf(
+			"tracer: invalid expected status range [%d, %d]", minStatus, maxStatus,
+		))
+		return rb
+	}
+	rb.minStatus, rb.maxStatus = minStatus, maxStatus
+	return rb
+}
+
+// Do 发送请求, 与 GetFasthttp 等方法相同, 会创建client端的span并将span信息打进请求头里, 且遵循ctx的deadline及取消; 请求失败时response为nil, 状态码不在 ExpectStatus 设置的范围内时同时返回response及 *HTTPError
+func (rb *RequestBuilder) Do() (response *Response, err error) {
+
+	response, err = rb.do(rb.minStatus, rb.maxStatus)
+	return
+}
+
+// do 发送请求并校验状态码是否在[minStatus, maxStatus]内, 均为0时不校验
+func (rb *RequestBuilder) do(minStatus, maxStatus int) (
+	response *Response, err error,
+) {
+
+	if rb.err != nil {
+		err = rb.err
+		return
+	}
+	var validate func(response *Response) error
+	if minStatus != 0 || maxStatus != 0 {
+		validate = func(response *Response) (err error) {
+			if response.StatusCode < minStatus || response.StatusCode > maxStatus {
+				err = newHTTPError(response)
+			}
+			return
+		}
+	}
+	response, err = rb.ti.doFasthttpReq(
+		rb.ctx, rb.url, validate, rb.prepare, rb.cbs...,
+	)
+	return
+}
+
+// prepare 将构造的内容设置到req中
+func (rb *RequestBuilder) prepare(req *fasthttp.Request) (err error) {
+
+	req.SetRequestURI(rb.url)
+	req.Header.SetMethod(rb.method)
+	var queryArgs = req.URI().QueryArgs()
+	for key, values := range rb.query {
+		for _, value := range values {
+			queryArgs.Add(key, value)
+		}
+	}
+	for key, values := range rb.header {
+		// 覆盖注入的同名头, 与 setFasthttpReqHeaderByMap 保持一致
+		req.Header.Del(key)
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	setFasthttpReqCookiesByMap(req, rb.cookies)
+	if rb.body != nil {
+		req.SetBody(rb.body)
+	}
+	if rb.contentType != emptyString {
+		req.Header.SetContentType(rb.contentType)
+	}
+	return
+}
+
+// setErr 记录构造过程中的第一个错误
+func (rb *RequestBuilder) setErr(err error) {
+
+	if rb.err == nil {
+		rb.err = err
+	}
+}
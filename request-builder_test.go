@@ -0,0 +1,175 @@
+package tracer
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/uber/jaeger-client-go"
+)
+
+// echoRequest 测试服务端收到的请求内容
+type echoRequest struct {
+	Method       string
+	Query        url.Values
+	Accept       []string
+	ContentType  string
+	Body         string
+	Form         url.Values
+	FileName     string
+	FileContent  string
+	TraceContext string
+}
+
+func TestRequestBuilder(t *testing.T) {
+
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var echo = echoRequest{
+			Method:       r.Method,
+			Query:        r.URL.Query(),
+			Accept:       r.Header.Values("Accept"),
+			ContentType:  r.Header.Get("Content-Type"),
+			TraceContext: r.Header.Get(OtMwTraceContextHeaderName),
+		}
+		if strings.HasPrefix(echo.ContentType, "multipart/form-data") {
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				t.Errorf("ParseMultipartForm() error = %v", err)
+			}
+			echo.Form = url.Values(r.MultipartForm.Value)
+			if file, header, err := r.FormFile("file"); err == nil {
+				var content, _ = io.ReadAll(file)
+				echo.FileName, echo.FileContent = header.Filename, string(content)
+			}
+		} else {
+			var body, _ = io.ReadAll(r.Body)
+			echo.Body = string(body)
+		}
+		w.Header().Add("X-Multi", "1")
+		w.Header().Add("X-Multi", "2")
+		json.NewEncoder(w).Encode(echo)
+	}))
+	defer srv.Close()
+	tests := []struct {
+		name  string
+		build func(rb *RequestBuilder) *RequestBuilder
+		want  echoRequest
+	}{
+		{
+			name: "patch json with query and multi-value header",
+			build: func(rb *RequestBuilder) *RequestBuilder {
+				return rb.Method(http.MethodPatch).URL(srv.URL+"/users/1?a=1").
+					Query("b", "2", "3").Header("Accept", "application/json", "text/plain").
+					JSON(map[string]string{"name": "a"})
+			},
+			want: echoRequest{
+				Method:      http.MethodPatch,
+				Query:       url.Values{"a": {"1"}, "b": {"2", "3"}},
+				Accept:      []string{"application/json", "text/plain"},
+				ContentType: "application/json",
+				Body:        `{"name":"a"}`,
+			},
+		},
+		{
+			name: "options raw bytes",
+			build: func(rb *RequestBuilder) *RequestBuilder {
+				return rb.Method(http.MethodOptions).URL(srv.URL).Body([]byte("raw"), "text/plain")
+			},
+			want: echoRequest{Method: http.MethodOptions, ContentType: "text/plain", Body: "raw"},
+		},
+		{
+			name: "form",
+			build: func(rb *RequestBuilder) *RequestBuilder {
+				return rb.Method(http.MethodPost).URL(srv.URL).Form(url.Values{"a": {"1", "2"}})
+			},
+			want: echoRequest{
+				Method:      http.MethodPost,
+				ContentType: "application/x-www-form-urlencoded",
+				Body:        "a=1&a=2",
+			},
+		},
+		{
+			name: "multipart",
+			build: func(rb *RequestBuilder) *RequestBuilder {
+				return rb.Method(http.MethodPut).URL(srv.URL).Multipart(
+					url.Values{"name": {"a"}},
+					MultipartFile{FieldName: "file", FileName: "a.txt", Content: strings.NewReader("content")},
+				)
+			},
+			want: echoRequest{
+				Method:      http.MethodPut,
+				Form:        url.Values{"name": {"a"}},
+				FileName:    "a.txt",
+				FileContent: "content",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ti, reporter = newTestTracer(t)
+			var resp, err = tt.build(ti.Request(context.Background())).Do()
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			if resp.StatusCode != http.StatusOK || !reflect.DeepEqual(resp.Header.Values("X-Multi"), []string{"1", "2"}) {
+				t.Errorf("StatusCode = %v, Header = %v", resp.StatusCode, resp.Header)
+			}
+			var got echoRequest
+			if err = json.Unmarshal(resp.Body, &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			var spanCtx, _ = jaeger.ContextFromString(got.TraceContext)
+			if reporter.SpansSubmitted() != 1 ||
+				spanCtx.SpanID() != reporter.GetSpans()[0].Context().(jaeger.SpanContext).SpanID() {
+				t.Errorf("span not injected, got %v", got.TraceContext)
+			}
+			got.TraceContext = ""
+			if strings.HasPrefix(got.ContentType, "multipart/form-data") {
+				got.ContentType = ""
+			}
+			if len(got.Query) == 0 {
+				got.Query = nil
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("request = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestBuilderHead(t *testing.T) {
+
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Method", r.Method)
+	}))
+	defer srv.Close()
+	var ti, _ = newTestTracer(t)
+	var resp, err = ti.Request(context.Background()).Method(http.MethodHead).URL(srv.URL).Do()
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if resp.Header.Get("X-Method") != http.MethodHead || len(resp.Body) != 0 {
+		t.Errorf("Header = %v, Body = %q", resp.Header, resp.Body)
+	}
+}
+
+func TestRequestBuilderError(t *testing.T) {
+
+	var ti, reporter = newTestTracer(t)
+	if _, err := ti.Request(context.Background()).Method(http.MethodPost).
+		URL("http://127.0.0.1:1").JSON(make(chan int)).Do(); err == nil {
+		t.Fatalf("Do() error = nil, want json error")
+	}
+	if _, err := ti.Request(context.Background()).URL("http://127.0.0.1:1").
+		ExpectStatus(299, 200).Do(); err == nil {
+		t.Fatalf("Do() error = nil, want invalid status range error")
+	}
+	if got := reporter.SpansSubmitted(); got != 0 {
+		t.Errorf("SpansSubmitted() = %v, want 0", got)
+	}
+}
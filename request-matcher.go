@@ -0,0 +1,141 @@
+package tracer
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+// RequestMatcher 判断http请求是否满足条件, 用于 WithSkipRequests, WithForceSampleRequests 及 WithNeverSampleRequests 指定 HttpMiddleWare 中需要特殊处理的请求, 也可直接传入自定义的判断函数
+type RequestMatcher func(r *http.Request) (matched bool)
+
+// WithSkipRequests 设置 HttpMiddleWare 不生成span的请求, 满足任一matcher即跳过, 可多次设置; 跳过的请求仍会将请求头中上游的span信息注入request.context中, handler中的下游调用可继续传递, example: WithSkipRequests(MatchPaths("/ping", "/healthz"), MatchPathPrefixes("/metrics"))
+func WithSkipRequests(matchers ...RequestMatcher) Option {
+	return func(opts *options) {
+		opts.skipRequestMatchers = appendRequestMatchers(
+			opts.skipRequestMatchers, matchers,
+		)
+	}
+}
+
+// WithForceSampleRequests 设置 HttpMiddleWare 中必定采样的请求, 满足任一matcher时无论采样策略及上游的采样决策如何都会采样(span将带有debug标记), 可多次设置
+func WithForceSampleRequests(matchers ...RequestMatcher) Option {
+	return func(opts *options) {
+		opts.forceSampleRequestMatchers = appendRequestMatchers(
+			opts.forceSampleRequestMatchers, matchers,
+		)
+	}
+}
+
+// WithNeverSampleRequests 设置 HttpMiddleWare 中不采样的请求, 满足任一matcher时无论采样策略及上游的采样决策如何都不采样, 不采样的决策会继续传递给下游; 与 WithForceSampleRequests 同时满足时以不采样为准, 可多次设置
+func WithNeverSampleRequests(matchers ...RequestMatcher) Option {
+	return func(opts *options) {
+		opts.neverSampleRequestMatchers = appendRequestMatchers(
+			opts.neverSampleRequestMatchers, matchers,
+		)
+	}
+}
+
+// MatchPaths 请求路径与paths中任一路径完全相同时满足条件
+func MatchPaths(paths ...string) RequestMatcher {
+
+	var set = make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		set[path] = struct{}{}
+	}
+	return func(r *http.Request) (matched bool) {
+
+		_, matched = set[r.URL.Path]
+		return
+	}
+}
+
+// MatchPathPrefixes 请求路径以prefixes中任一前缀开头时满足条件
+func MatchPathPrefixes(prefixes ...string) RequestMatcher {
+	return func(r *http.Request) (matched bool) {
+
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				return true
+			}
+		}
+		return
+	}
+}
+
+// MatchPathRegexp 请求路径匹配正则表达式expr时满足条件, example: MatchPathRegexp(regexp.MustCompile(`^/debug/pprof/`))
+func MatchPathRegexp(expr *regexp.Regexp) RequestMatcher {
+	return func(r *http.Request) (matched bool) {
+
+		matched = expr.MatchString(r.URL.Path)
+		return
+	}
+}
+
+// MatchMethods 请求方法为methods中任一方法时满足条件, example: MatchMethods(http.MethodOptions)
+func MatchMethods(methods ...string) RequestMatcher {
+	return func(r *http.Request) (matched bool) {
+
+		for _, method := range methods {
+			if r.Method == method {
+				return true
+			}
+		}
+		return
+	}
+}
+
+// MatchAll 同时满足所有matcher时满足条件, example: MatchAll(MatchMethods(http.MethodGet), MatchPaths("/metrics"))
+func MatchAll(matchers ...RequestMatcher) RequestMatcher {
+	return func(r *http.Request) (matched bool) {
+
+		for _, matcher := range matchers {
+			if !matcher(r) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// appendRequestMatchers 将matchers中非nil的matcher追加到dst中
+func appendRequestMatchers(dst, matchers []RequestMatcher) []RequestMatcher {
+
+	for _, matcher := range matchers {
+		if matcher != nil {
+			dst = append(dst, matcher)
+		}
+	}
+	return dst
+}
+
+// matchRequest 判断r是否满足matchers中任一matcher
+func matchRequest(matchers []RequestMatcher, r *http.Request) bool {
+
+	for _, matcher := range matchers {
+		if matcher(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// samplingPriorityOptions 根据 WithForceSampleRequests 及 WithNeverSampleRequests 的设置, 返回创建span时设置 sampling.priority 的选项
+func (ti *tracerImpl) samplingPriorityOptions(r *http.Request) (
+	opts []opentracing.StartSpanOption,
+) {
+
+	if matchRequest(ti.neverSampleRequestMatchers, r) {
+		opts = append(opts, opentracing.Tag{
+			Key: string(ext.SamplingPriority), Value: uint16(0),
+		})
+	} else if matchRequest(ti.forceSampleRequestMatchers, r) {
+		opts = append(opts, opentracing.Tag{
+			Key: string(ext.SamplingPriority), Value: uint16(1),
+		})
+	}
+	return
+}
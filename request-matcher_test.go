@@ -0,0 +1,128 @@
+package tracer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/uber/jaeger-client-go"
+)
+
+func TestRequestMatcher(t *testing.T) {
+	tests := []struct {
+		name    string
+		matcher RequestMatcher
+		method  string
+		url     string
+		want    bool
+	}{
+		{name: "paths", matcher: MatchPaths("/ping", "/healthz"), url: "/healthz", want: true},
+		{name: "paths not exact", matcher: MatchPaths("/ping"), url: "/ping/1"},
+		{name: "paths ignore query", matcher: MatchPaths("/ping"), url: "/ping?a=1", want: true},
+		{name: "prefixes", matcher: MatchPathPrefixes("/metrics", "/debug/"), url: "/debug/vars", want: true},
+		{name: "prefixes not matched", matcher: MatchPathPrefixes("/debug/"), url: "/users/debug/"},
+		{
+			name: "regexp", matcher: MatchPathRegexp(regexp.MustCompile(`^/static/.*\.js$`)),
+			url: "/static/app.js", want: true,
+		},
+		{
+			name: "methods", matcher: MatchMethods(http.MethodOptions, http.MethodHead),
+			method: http.MethodOptions, url: "/users", want: true,
+		},
+		{
+			name:    "all",
+			matcher: MatchAll(MatchMethods(http.MethodGet), MatchPaths("/metrics")),
+			url:     "/metrics", want: true,
+		},
+		{
+			name:    "all not matched",
+			matcher: MatchAll(MatchMethods(http.MethodGet), MatchPaths("/metrics")),
+			method:  http.MethodPost, url: "/metrics",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var method = tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			if got := tt.matcher(httptest.NewRequest(method, tt.url, nil)); got != tt.want {
+				t.Errorf("matcher() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHttpMiddleWareRequestMatchers(t *testing.T) {
+
+	var opts = []Option{
+		WithProbabilisticSampler(0),
+		WithSkipRequests(MatchPaths("/ping"), nil),
+		WithForceSampleRequests(MatchPathPrefixes("/orders/", "/debug/")),
+		WithNeverSampleRequests(MatchPathPrefixes("/debug/")),
+	}
+	tests := []struct {
+		name         string
+		url          string
+		parentHeader string
+		wantReported int
+		wantSampled  bool
+		wantParentID jaeger.SpanID
+	}{
+		{name: "skipped root", url: "/ping"},
+		{
+			name: "skipped keeps parent", url: "/ping", parentHeader: "4d2:4d2:0:1",
+			wantSampled: true, wantParentID: 0x4d2,
+		},
+		{name: "force sample", url: "/orders/1", wantReported: 1, wantSampled: true},
+		{
+			name: "force sample unsampled parent", url: "/orders/1",
+			parentHeader: "4d2:4d2:0:0", wantReported: 1, wantSampled: true,
+		},
+		{name: "never sample wins", url: "/debug/vars", parentHeader: "4d2:4d2:0:1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ti, reporter = newTestTracer(t, opts...)
+			var downstream = http.Header{}
+			var handler = ti.HttpMiddleWare(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					var span = ti.ChildSpanFromContext("downstream", r.Context())
+					defer span.Finish()
+					if err := ti.Inject2HttpHeader(span, downstream); err != nil {
+						t.Errorf("Inject2HttpHeader() error = %v", err)
+					}
+				},
+			))
+			var req = httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.parentHeader != "" {
+				req.Header.Set(OtMwTraceContextHeaderName, tt.parentHeader)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			var spanCtx, err = jaeger.ContextFromString(
+				downstream.Get(OtMwTraceContextHeaderName),
+			)
+			if err != nil {
+				t.Fatalf("downstream header not propagated: %v", err)
+			}
+			if spanCtx.IsSampled() != tt.wantSampled {
+				t.Errorf("IsSampled() = %v, want %v", spanCtx.IsSampled(), tt.wantSampled)
+			}
+			if tt.wantParentID != 0 && spanCtx.ParentID() != tt.wantParentID {
+				t.Errorf("ParentID() = %v, want %v", spanCtx.ParentID(), tt.wantParentID)
+			}
+			var reported = reporter.GetSpans()
+			var middleWareSpans int
+			for _, span := range reported {
+				if span.(*jaeger.Span).OperationName() != "downstream" {
+					middleWareSpans++
+				}
+			}
+			if middleWareSpans != tt.wantReported {
+				t.Errorf("reported middleware spans = %v, want %v", middleWareSpans, tt.wantReported)
+			}
+		})
+	}
+}
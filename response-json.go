@@ -0,0 +1,52 @@
+package tracer
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// httpErrorBodySnippetLength HTTPError.Body 最多保留的响应体字节数
+const httpErrorBodySnippetLength = 512
+
+// HTTPError 状态码不在 RequestBuilder.ExpectStatus 设置的成功范围内时返回的错误, 可通过 errors.As 获取
+type HTTPError struct {
+	// StatusCode http状态码
+	StatusCode int
+	// Body 响应体的前512个字节, 便于排查问题, 完整的响应体见 Response.Body
+	Body []byte
+}
+
+func newHTTPError(response *Response) (httpErr *HTTPError) {
+
+	var body = response.Body
+	if len(body) > httpErrorBodySnippetLength {
+		body = body[:httpErrorBodySnippetLength]
+	}
+	httpErr = &HTTPError{StatusCode: response.StatusCode, Body: body}
+	return
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf(
+		"tracer: unexpected http status %d %s: %s",
+		e.StatusCode, http.StatusText(e.StatusCode), e.Body,
+	)
+}
+
+// DoJSON 通过 RequestBuilder.Do 发送请求, 并将响应体通过json反序列化为T; 未通过 RequestBuilder.ExpectStatus 设置(或设置为(0, 0))时成功的状态码范围为[200, 299], 不会修改rb, 状态码不在该范围内时返回 *HTTPError 并将span标记为 error=true; 响应体为空时返回T的零值, example: user, resp, err := DoJSON[User](tracer.Request(ctx).URL("http://127.0.0.1:8080/users/1"))
+func DoJSON[T any](rb *RequestBuilder) (
+	result T, response *Response, err error,
+) {
+
+	var minStatus, maxStatus = rb.minStatus, rb.maxStatus
+	if minStatus == 0 && maxStatus == 0 {
+		minStatus, maxStatus = http.StatusOK, http.StatusMultipleChoices-1
+	}
+	if response, err = rb.do(minStatus, maxStatus); err != nil {
+		return
+	}
+	if len(response.Body) != 0 {
+		err = jsonSerializer.Unmarshal(response.Body, &result)
+	}
+	return
+}
@@ -0,0 +1,101 @@
+package tracer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/uber/jaeger-client-go"
+)
+
+func TestDoJSON(t *testing.T) {
+
+	type user struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			io.WriteString(w, `{"id":1,"name":"a"}`)
+		case "/empty":
+			w.WriteHeader(http.StatusNoContent)
+		case "/not-found":
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"msg":"not found"}`)
+		case "/large":
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, strings.Repeat("x", 2*httpErrorBodySnippetLength))
+		case "/invalid":
+			io.WriteString(w, `not json`)
+		}
+	}))
+	defer srv.Close()
+	tests := []struct {
+		name           string
+		path           string
+		minStatus      int
+		maxStatus      int
+		want           user
+		wantStatusCode int
+		wantHTTPErr    bool
+		wantErr        bool
+		wantSnippet    int
+	}{
+		{name: "ok", path: "/ok", want: user{ID: 1, Name: "a"}},
+		{name: "empty body", path: "/empty"},
+		{name: "not found", path: "/not-found", wantHTTPErr: true, wantStatusCode: http.StatusNotFound, wantSnippet: 19},
+		{
+			name: "custom success range", path: "/not-found", minStatus: 200, maxStatus: 404,
+		},
+		{
+			name: "body snippet", path: "/large", wantHTTPErr: true,
+			wantStatusCode: http.StatusBadRequest, wantSnippet: httpErrorBodySnippetLength,
+		},
+		{name: "invalid json", path: "/invalid", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ti, reporter = newTestTracer(t)
+			var rb = ti.Request(context.Background()).URL(srv.URL + tt.path)
+			if tt.maxStatus != 0 {
+				rb.ExpectStatus(tt.minStatus, tt.maxStatus)
+			}
+			var got, resp, err = DoJSON[user](rb)
+			if resp == nil {
+				t.Fatalf("DoJSON() response = nil, error = %v", err)
+			}
+			if rb.minStatus != tt.minStatus || rb.maxStatus != tt.maxStatus {
+				t.Errorf("DoJSON() changed status range to [%v, %v]", rb.minStatus, rb.maxStatus)
+			}
+			var httpErr *HTTPError
+			if errors.As(err, &httpErr) != tt.wantHTTPErr {
+				t.Fatalf("DoJSON() error = %v, wantHTTPErr %v", err, tt.wantHTTPErr)
+			}
+			if !tt.wantHTTPErr && (err != nil) != tt.wantErr {
+				t.Fatalf("DoJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("DoJSON() = %v, want %v", got, tt.want)
+			}
+			var tags = reporter.GetSpans()[0].(*jaeger.Span).Tags()
+			if (tags[string(ext.Error)] == true) != tt.wantHTTPErr {
+				t.Errorf("tag error = %v, want %v", tags[string(ext.Error)], tt.wantHTTPErr)
+			}
+			if !tt.wantHTTPErr {
+				return
+			}
+			if httpErr.StatusCode != tt.wantStatusCode || len(httpErr.Body) != tt.wantSnippet {
+				t.Errorf("HTTPError = %v, body length %v", httpErr, len(httpErr.Body))
+			}
+			if resp.StatusCode != tt.wantStatusCode {
+				t.Errorf("StatusCode = %v, want %v", resp.StatusCode, tt.wantStatusCode)
+			}
+		})
+	}
+}
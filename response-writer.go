@@ -0,0 +1,222 @@
+package tracer
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+)
+
+const (
+	flusherFlag = 1 << iota
+	hijackerFlag
+	pusherFlag
+	readerFromFlag
+)
+
+// trackedResponseWriter newStatusCodeTracker 返回的 http.ResponseWriter 均实现的接口, Unwrap 供 http.ResponseController 获取原始的 http.ResponseWriter
+type trackedResponseWriter interface {
+	http.ResponseWriter
+	Unwrap() http.ResponseWriter
+}
+
+// statusCodeTracker 记录handler返回的状态码及写入的字节数; 只调用Write而未调用WriteHeader时, 与 http.ResponseWriter 的行为一致记录为200
+type statusCodeTracker struct {
+	http.ResponseWriter
+	statusCode   int
+	bytesWritten int64
+	wroteHeader  bool
+	// body 开启body记录时记录写入的响应体, 否则为nil
+	body *captureBuffer
+}
+
+// newStatusCodeTracker 包装w, 返回的 http.ResponseWriter 仅实现w实现了的 http.Flusher, http.Hijacker, http.Pusher 及 io.ReaderFrom 接口, 避免SSE, websocket等依赖这些接口的功能在中间件后失效, 同时不会让调用方误以为w支持其未实现的接口
+func newStatusCodeTracker(w http.ResponseWriter) (
+	sct *statusCodeTracker, wrapped http.ResponseWriter,
+) {
+
+	sct = &statusCodeTracker{ResponseWriter: w}
+	var flags int
+	if _, ok := w.(http.Flusher); ok {
+		flags |= flusherFlag
+	}
+	if _, ok := w.(http.Hijacker); ok {
+		flags |= hijackerFlag
+	}
+	if _, ok := w.(http.Pusher); ok {
+		flags |= pusherFlag
+	}
+	if _, ok := w.(io.ReaderFrom); ok {
+		flags |= readerFromFlag
+	}
+
+	var tw trackedResponseWriter = sct
+	switch flags {
+	case 0:
+		// *statusCodeTracker 实现了所有接口, 需要再包装一层
+		wrapped = struct{ trackedResponseWriter }{tw}
+	case flusherFlag:
+		wrapped = struct {
+			trackedResponseWriter
+			http.Flusher
+		}{tw, sct}
+	case hijackerFlag:
+		wrapped = struct {
+			trackedResponseWriter
+			http.Hijacker
+		}{tw, sct}
+	case flusherFlag | hijackerFlag:
+		wrapped = struct {
+			trackedResponseWriter
+			http.Flusher
+			http.Hijacker
+		}{tw, sct, sct}
+	case pusherFlag:
+		wrapped = struct {
+			trackedResponseWriter
+			http.Pusher
+		}{tw, sct}
+	case flusherFlag | pusherFlag:
+		wrapped = struct {
+			trackedResponseWriter
+			http.Flusher
+			http.Pusher
+		}{tw, sct, sct}
+	case hijackerFlag | pusherFlag:
+		wrapped = struct {
+			trackedResponseWriter
+			http.Hijacker
+			http.Pusher
+		}{tw, sct, sct}
+	case flusherFlag | hijackerFlag | pusherFlag:
+		wrapped = struct {
+			trackedResponseWriter
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+		}{tw, sct, sct, sct}
+	case readerFromFlag:
+		wrapped = struct {
+			trackedResponseWriter
+			io.ReaderFrom
+		}{tw, sct}
+	case flusherFlag | readerFromFlag:
+		wrapped = struct {
+			trackedResponseWriter
+			http.Flusher
+			io.ReaderFrom
+		}{tw, sct, sct}
+	case hijackerFlag | readerFromFlag:
+		wrapped = struct {
+			trackedResponseWriter
+			http.Hijacker
+			io.ReaderFrom
+		}{tw, sct, sct}
+	case flusherFlag | hijackerFlag | readerFromFlag:
+		// 标准库http/1.x的 http.ResponseWriter
+		wrapped = struct {
+			trackedResponseWriter
+			http.Flusher
+			http.Hijacker
+			io.ReaderFrom
+		}{tw, sct, sct, sct}
+	case pusherFlag | readerFromFlag:
+		wrapped = struct {
+			trackedResponseWriter
+			http.Pusher
+			io.ReaderFrom
+		}{tw, sct, sct}
+	case flusherFlag | pusherFlag | readerFromFlag:
+		wrapped = struct {
+			trackedResponseWriter
+			http.Flusher
+			http.Pusher
+			io.ReaderFrom
+		}{tw, sct, sct, sct}
+	case hijackerFlag | pusherFlag | readerFromFlag:
+		wrapped = struct {
+			trackedResponseWriter
+			http.Hijacker
+			http.Pusher
+			io.ReaderFrom
+		}{tw, sct, sct, sct}
+	default:
+		wrapped = struct {
+			trackedResponseWriter
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+			io.ReaderFrom
+		}{tw, sct, sct, sct, sct}
+	}
+	return
+}
+
+func (w *statusCodeTracker) WriteHeader(statusCode int) {
+
+	if !w.wroteHeader {
+		w.statusCode = statusCode
+		// 1xx(101除外)为临时响应, 之后还会写入最终的状态码
+		w.wroteHeader = statusCode >= http.StatusOK ||
+			statusCode == http.StatusSwitchingProtocols
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *statusCodeTracker) Write(b []byte) (n int, err error) {
+
+	w.writeImplicitHeader()
+	n, err = w.ResponseWriter.Write(b)
+	w.bytesWritten += int64(n)
+	if w.body != nil {
+		w.body.Write(b[:n])
+	}
+	return
+}
+
+func (w *statusCodeTracker) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *statusCodeTracker) Flush() {
+
+	w.writeImplicitHeader()
+	w.ResponseWriter.(http.Flusher).Flush()
+}
+
+func (w *statusCodeTracker) Hijack() (
+	conn net.Conn, rw *bufio.ReadWriter, err error,
+) {
+
+	if conn, rw, err = w.ResponseWriter.(http.Hijacker).Hijack(); err == nil &&
+		!w.wroteHeader {
+		// 接管连接后的响应(如websocket握手)由handler直接写入conn, 无法获取
+		w.statusCode = http.StatusSwitchingProtocols
+		w.wroteHeader = true
+	}
+	return
+}
+
+func (w *statusCodeTracker) Push(target string, opts *http.PushOptions) error {
+	return w.ResponseWriter.(http.Pusher).Push(target, opts)
+}
+
+func (w *statusCodeTracker) ReadFrom(r io.Reader) (n int64, err error) {
+
+	w.writeImplicitHeader()
+	if w.body != nil {
+		r = io.TeeReader(r, w.body)
+	}
+	n, err = w.ResponseWriter.(io.ReaderFrom).ReadFrom(r)
+	w.bytesWritten += n
+	return
+}
+
+// writeImplicitHeader 未调用WriteHeader而直接写入响应体时, http.ResponseWriter 会以200作为状态码
+func (w *statusCodeTracker) writeImplicitHeader() {
+
+	if !w.wroteHeader {
+		w.statusCode = http.StatusOK
+		w.wroteHeader = true
+	}
+}
@@ -0,0 +1,147 @@
+package tracer
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/uber/jaeger-client-go"
+)
+
+func TestHttpMiddleWareStatusCode(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        http.HandlerFunc
+		wantStatusCode uint16
+		wantBytes      int64
+	}{
+		{
+			name:           "write only",
+			handler:        func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("pong")) },
+			wantStatusCode: http.StatusOK,
+			wantBytes:      4,
+		},
+		{
+			name:           "no write",
+			handler:        func(w http.ResponseWriter, r *http.Request) {},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name: "explicit status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte("not found"))
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBytes:      9,
+		},
+		{
+			name: "informational before final status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusEarlyHints)
+				w.WriteHeader(http.StatusCreated)
+			},
+			wantStatusCode: http.StatusCreated,
+		},
+		{
+			name: "read from",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				io.Copy(w, strings.NewReader("streamed"))
+			},
+			wantStatusCode: http.StatusOK,
+			wantBytes:      8,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ti, reporter = newTestTracer(t)
+			var srv = httptest.NewServer(ti.HttpMiddleWare(tt.handler))
+			defer srv.Close()
+			var resp, err = http.Get(srv.URL)
+			if err != nil {
+				t.Fatalf("http.Get() error = %v", err)
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode != int(tt.wantStatusCode) {
+				t.Errorf("StatusCode = %v, want %v", resp.StatusCode, tt.wantStatusCode)
+			}
+
+			srv.Close()
+			var tags = reporter.GetSpans()[0].(*jaeger.Span).Tags()
+			if tags[string(ext.HTTPStatusCode)] != tt.wantStatusCode {
+				t.Errorf("tag http.status_code = %v, want %v", tags[string(ext.HTTPStatusCode)], tt.wantStatusCode)
+			}
+			if tags[tagKeyHttpResponseSize] != tt.wantBytes {
+				t.Errorf("tag %v = %v, want %v", tagKeyHttpResponseSize, tags[tagKeyHttpResponseSize], tt.wantBytes)
+			}
+		})
+	}
+}
+
+func TestNewStatusCodeTrackerInterfaces(t *testing.T) {
+
+	var check = func(t *testing.T, original, wrapped http.ResponseWriter) {
+
+		t.Helper()
+		var _, wantFlusher = original.(http.Flusher)
+		var _, wantHijacker = original.(http.Hijacker)
+		var _, wantPusher = original.(http.Pusher)
+		var _, wantReaderFrom = original.(io.ReaderFrom)
+		if _, ok := wrapped.(http.Flusher); ok != wantFlusher {
+			t.Errorf("http.Flusher = %v, want %v", ok, wantFlusher)
+		}
+		if _, ok := wrapped.(http.Hijacker); ok != wantHijacker {
+			t.Errorf("http.Hijacker = %v, want %v", ok, wantHijacker)
+		}
+		if _, ok := wrapped.(http.Pusher); ok != wantPusher {
+			t.Errorf("http.Pusher = %v, want %v", ok, wantPusher)
+		}
+		if _, ok := wrapped.(io.ReaderFrom); ok != wantReaderFrom {
+			t.Errorf("io.ReaderFrom = %v, want %v", ok, wantReaderFrom)
+		}
+		if u, ok := wrapped.(interface{ Unwrap() http.ResponseWriter }); !ok || u.Unwrap() != original {
+			t.Errorf("Unwrap() not return the original http.ResponseWriter")
+		}
+	}
+
+	t.Run("bare", func(t *testing.T) {
+		var original = struct{ http.ResponseWriter }{httptest.NewRecorder()}
+		var _, wrapped = newStatusCodeTracker(original)
+		check(t, original, wrapped)
+	})
+	t.Run("recorder", func(t *testing.T) {
+		var original = httptest.NewRecorder()
+		var sct, wrapped = newStatusCodeTracker(original)
+		check(t, original, wrapped)
+		if err := http.NewResponseController(wrapped).Flush(); err != nil {
+			t.Errorf("ResponseController.Flush() error = %v", err)
+		}
+		if sct.statusCode != http.StatusOK || !original.Flushed {
+			t.Errorf("statusCode = %v, Flushed = %v after Flush()", sct.statusCode, original.Flushed)
+		}
+	})
+	t.Run("http1 server", func(t *testing.T) {
+		var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var sct, wrapped = newStatusCodeTracker(w)
+			check(t, w, wrapped)
+			var conn, _, err = wrapped.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Errorf("Hijack() error = %v", err)
+				return
+			}
+			defer conn.Close()
+			conn.Write([]byte("HTTP/1.1 101 Switching Protocols\r\nConnection: close\r\n\r\n"))
+			if sct.statusCode != http.StatusSwitchingProtocols {
+				t.Errorf("statusCode = %v after Hijack(), want 101", sct.statusCode)
+			}
+		}))
+		defer srv.Close()
+		if resp, err := http.Get(srv.URL); err == nil {
+			resp.Body.Close()
+		}
+	})
+}
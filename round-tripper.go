@@ -0,0 +1,165 @@
+package tracer
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"sync"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	opentracingLog "github.com/opentracing/opentracing-go/log"
+)
+
+func (ti *tracerImpl) HttpRoundTripper(base http.RoundTripper) (
+	traced http.RoundTripper,
+) {
+
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if ti == noopTracerImpl {
+		traced = base
+		return
+	}
+	traced = &tracedRoundTripper{ti: ti, base: base}
+	return
+}
+
+// tracedRoundTripper HttpRoundTripper 返回的 http.RoundTripper
+type tracedRoundTripper struct {
+	ti   *tracerImpl
+	base http.RoundTripper
+}
+
+func (trt *tracedRoundTripper) RoundTrip(req *http.Request) (
+	resp *http.Response, err error,
+) {
+
+	var child = trt.ti.ChildSpanFromContext(
+		getOperationNameFromHttpRequest(req), req.Context(),
+	)
+	setHttpClientSpanTags(child, req)
+	var ctx = httptrace.WithClientTrace(
+		trt.ti.ContextWithSpan(req.Context(), child), newHttpClientTrace(child),
+	)
+	// http.RoundTripper 不可修改传入的请求
+	req = req.Clone(ctx)
+	if err = trt.ti.Inject2HttpHeaderByCtx(ctx, req.Header); err != nil {
+		child.Finish()
+		return
+	}
+
+	resp, err = trt.base.RoundTrip(req)
+	setHttpClientRespSpanTags(child, resp, err)
+	if err != nil || resp.Body == nil || resp.Body == http.NoBody {
+		child.Finish()
+		return
+	}
+	var body = &spanFinishingBody{ReadCloser: resp.Body, span: child}
+	if rwc, ok := resp.Body.(io.ReadWriteCloser); ok {
+		// 101 Switching Protocols时响应体可写, 需保留 io.Writer 接口
+		resp.Body = struct {
+			*spanFinishingBody
+			io.Writer
+		}{body, rwc}
+	} else {
+		resp.Body = body
+	}
+	return
+}
+
+// spanFinishingBody 在响应体读取完毕, 读取出错或关闭时结束span, 使span的耗时包含读取响应体的时间
+type spanFinishingBody struct {
+	io.ReadCloser
+	span opentracing.Span
+	once sync.Once
+}
+
+func (sfb *spanFinishingBody) Read(p []byte) (n int, err error) {
+
+	n, err = sfb.ReadCloser.Read(p)
+	if err == io.EOF {
+		sfb.finish()
+	} else if err != nil {
+		sfb.once.Do(func() {
+			ext.Error.Set(sfb.span, true)
+			sfb.span.LogFields(opentracingLog.Error(err))
+			sfb.span.Finish()
+		})
+	}
+	return
+}
+
+func (sfb *spanFinishingBody) Close() (err error) {
+
+	err = sfb.ReadCloser.Close()
+	sfb.finish()
+	return
+}
+
+func (sfb *spanFinishingBody) finish() {
+	sfb.once.Do(sfb.span.Finish)
+}
+
+// newHttpClientTrace 返回将DNS解析, 建立连接, TLS握手, 收到首字节等事件记录到span中的 httptrace.ClientTrace; 通过 httptrace.WithClientTrace 设置, 与调用方在ctx中设置的 httptrace.ClientTrace 同时生效
+func newHttpClientTrace(span opentracing.Span) *httptrace.ClientTrace {
+
+	var logEvent = func(event string, err error) {
+		if err == nil {
+			span.LogFields(opentracingLog.String("event", event))
+		} else {
+			span.LogFields(
+				opentracingLog.String("event", event), opentracingLog.Error(err),
+			)
+		}
+	}
+	return &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			span.LogFields(
+				opentracingLog.String("event", "GetConn"),
+				opentracingLog.String("host_port", hostPort),
+			)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			span.LogFields(
+				opentracingLog.String("event", "GotConn"),
+				opentracingLog.Bool("reused", info.Reused),
+				opentracingLog.Bool("was_idle", info.WasIdle),
+			)
+			if addr, ok := info.Conn.RemoteAddr().(*net.TCPAddr); ok {
+				setPeerSpanTags(span, addr.IP.String(), strconv.Itoa(addr.Port))
+			}
+		},
+		DNSStart: func(info httptrace.DNSStartInfo) {
+			span.LogFields(
+				opentracingLog.String("event", "DNSStart"),
+				opentracingLog.String("host", info.Host),
+			)
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			logEvent("DNSDone", info.Err)
+		},
+		ConnectStart: func(network, addr string) {
+			span.LogFields(
+				opentracingLog.String("event", "ConnectStart"),
+				opentracingLog.String("network", network),
+				opentracingLog.String("addr", addr),
+			)
+		},
+		ConnectDone: func(network, addr string, err error) {
+			logEvent("ConnectDone", err)
+		},
+		TLSHandshakeStart: func() { logEvent("TLSHandshakeStart", nil) },
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			logEvent("TLSHandshakeDone", err)
+		},
+		WroteRequest: func(info httptrace.WroteRequestInfo) {
+			logEvent("WroteRequest", info.Err)
+		},
+		GotFirstResponseByte: func() { logEvent("GotFirstResponseByte", nil) },
+	}
+}
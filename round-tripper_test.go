@@ -0,0 +1,123 @@
+package tracer
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"testing"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/uber/jaeger-client-go"
+)
+
+func TestHttpRoundTripper(t *testing.T) {
+
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Got-Trace-Context", r.Header.Get(OtMwTraceContextHeaderName))
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+		io.WriteString(w, "pong")
+	}))
+	defer srv.Close()
+	tests := []struct {
+		name       string
+		path       string
+		statusCode int
+		wantError  bool
+	}{
+		{name: "ok", path: "/pong", statusCode: http.StatusOK},
+		{name: "5xx", path: "/fail", statusCode: http.StatusBadGateway, wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ti, reporter = newTestTracer(t)
+			var parent = ti.StartSpan("parent")
+			defer parent.Finish()
+			var gotFirstByte bool
+			var ctx = httptrace.WithClientTrace(
+				ti.ContextWithSpan(context.Background(), parent),
+				&httptrace.ClientTrace{GotFirstResponseByte: func() { gotFirstByte = true }},
+			)
+			var req, _ = http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+tt.path, nil)
+			var client = &http.Client{Transport: ti.HttpRoundTripper(nil)}
+			var resp, err = client.Do(req)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			if len(req.Header) != 0 {
+				t.Errorf("request modified, Header() = %v", req.Header)
+			}
+			if !gotFirstByte {
+				t.Errorf("caller's httptrace.ClientTrace not called")
+			}
+			if got := reporter.SpansSubmitted(); got != 0 {
+				t.Errorf("SpansSubmitted() = %v before body closed, want 0", got)
+			}
+			if body, _ := io.ReadAll(resp.Body); string(body) != "pong" {
+				t.Errorf("body = %q, want pong", body)
+			}
+			resp.Body.Close()
+			if got := reporter.SpansSubmitted(); got != 1 {
+				t.Fatalf("SpansSubmitted() = %v, want 1", got)
+			}
+
+			var span = reporter.GetSpans()[0].(*jaeger.Span)
+			var spanCtx, _ = jaeger.ContextFromString(resp.Header.Get("Got-Trace-Context"))
+			if spanCtx.SpanID() != span.SpanContext().SpanID() ||
+				span.SpanContext().ParentID() != parent.Context().(jaeger.SpanContext).SpanID() {
+				t.Errorf("injected %v, want span %v child of parent", spanCtx, span.SpanContext())
+			}
+			if span.OperationName() != "HTTP GET "+tt.path {
+				t.Errorf("OperationName() = %v", span.OperationName())
+			}
+			var tags = span.Tags()
+			var want = map[string]interface{}{
+				string(ext.SpanKind):       ext.SpanKindRPCClientEnum,
+				string(ext.HTTPMethod):     http.MethodGet,
+				string(ext.HTTPUrl):        srv.URL + tt.path,
+				string(ext.HTTPStatusCode): uint16(tt.statusCode),
+				string(ext.PeerPort):       uint16(srv.Listener.Addr().(*net.TCPAddr).Port),
+			}
+			for key, val := range want {
+				if tags[key] != val {
+					t.Errorf("tag %v = %v, want %v", key, tags[key], val)
+				}
+			}
+			if _, isErr := tags[string(ext.Error)]; isErr != tt.wantError {
+				t.Errorf("tag error = %v, want %v", tags[string(ext.Error)], tt.wantError)
+			}
+			var events = map[string]bool{}
+			for _, log := range span.Logs() {
+				for _, field := range log.Fields {
+					if field.Key() == "event" {
+						events[field.Value().(string)] = true
+					}
+				}
+			}
+			for _, event := range []string{"GetConn", "GotConn", "WroteRequest", "GotFirstResponseByte"} {
+				if !events[event] {
+					t.Errorf("event %v not logged, got %v", event, events)
+				}
+			}
+		})
+	}
+}
+
+func TestHttpRoundTripperError(t *testing.T) {
+
+	var ti, reporter = newTestTracer(t)
+	var client = &http.Client{Transport: ti.HttpRoundTripper(nil)}
+	if _, err := client.Get("http://127.0.0.1:1/"); err == nil {
+		t.Fatalf("Get() error = nil, want connection error")
+	}
+	if got := reporter.SpansSubmitted(); got != 1 {
+		t.Fatalf("SpansSubmitted() = %v, want 1", got)
+	}
+	if tags := reporter.GetSpans()[0].(*jaeger.Span).Tags(); tags[string(ext.Error)] != true {
+		t.Errorf("tag error = %v, want true", tags[string(ext.Error)])
+	}
+}
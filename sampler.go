@@ -0,0 +1,89 @@
+package tracer
+
+import (
+	"time"
+
+	"github.com/uber/jaeger-client-go"
+	jaegerCfg "github.com/uber/jaeger-client-go/config"
+	"github.com/uber/jaeger-client-go/thrift-gen/sampling"
+)
+
+// WithConstSampler 固定采样策略, sample为true时追踪所有请求, 为false时不追踪任何请求; 上游已做出采样决策的请求仍以上游的决策为准
+func WithConstSampler(sample bool) Option {
+
+	var param float64
+	if sample {
+		param = 1
+	}
+	return WithSamplerConfig(&jaegerCfg.SamplerConfig{
+		Type:  jaeger.SamplerTypeConst,
+		Param: param,
+	})
+}
+
+// WithProbabilisticSampler 概率采样策略, samplingRate取值范围为[0, 1], 如0.01表示采样1%的trace; 上游已做出采样决策的请求仍以上游的决策为准
+func WithProbabilisticSampler(samplingRate float64) Option {
+	return WithSamplerConfig(&jaegerCfg.SamplerConfig{
+		Type:  jaeger.SamplerTypeProbabilistic,
+		Param: samplingRate,
+	})
+}
+
+// WithRateLimitingSampler 限速采样策略, 每秒最多采样maxTracesPerSecond个trace; 上游已做出采样决策的请求仍以上游的决策为准
+func WithRateLimitingSampler(maxTracesPerSecond float64) Option {
+	return WithSamplerConfig(&jaegerCfg.SamplerConfig{
+		Type:  jaeger.SamplerTypeRateLimiting,
+		Param: maxTracesPerSecond,
+	})
+}
+
+// WithPerOperationSampler 按操作名称(opName)采样的策略, operationSamplingRates中的操作按各自的概率采样, 其余操作按defaultSamplingRate采样; 每个操作每秒至少采样lowerBoundTracesPerSecond个trace, 保证低频操作也能被追踪到; 最多跟踪maxOperations个操作, 超出的操作使用默认策略, maxOperations<=0时使用jaeger的默认值; 采样器在 NewTracer 时创建, 同一个Option可用于多个tracer; 与 WithSamplerConfig 及 WithSampler 同时设置时以最后设置的为准
+func WithPerOperationSampler(
+	defaultSamplingRate, lowerBoundTracesPerSecond float64,
+	operationSamplingRates map[string]float64, maxOperations int,
+) Option {
+
+	var strategies = &sampling.PerOperationSamplingStrategies{
+		DefaultSamplingProbability:       defaultSamplingRate,
+		DefaultLowerBoundTracesPerSecond: lowerBoundTracesPerSecond,
+		PerOperationStrategies: make(
+			[]*sampling.OperationSamplingStrategy,
+			0, len(operationSamplingRates),
+		),
+	}
+	for opName, samplingRate := range operationSamplingRates {
+		strategies.PerOperationStrategies = append(
+			strategies.PerOperationStrategies,
+			&sampling.OperationSamplingStrategy{
+				Operation: opName,
+				ProbabilisticSampling: &sampling.ProbabilisticSamplingStrategy{
+					SamplingRate: samplingRate,
+				},
+			},
+		)
+	}
+	return func(opts *options) {
+		opts.newSampler = func() jaeger.Sampler {
+			return jaeger.NewPerOperationSampler(
+				jaeger.PerOperationSamplerParams{
+					MaxOperations: maxOperations,
+					Strategies:    strategies,
+				},
+			)
+		}
+	}
+}
+
+// WithRemoteSampler 远程采样策略, 每隔refreshInterval从兼容jaeger的采样服务拉取本服务的采样策略(GET samplingServerURL?service=srvName)并在运行中热更新, 无需重启即可调整整体或某个操作(opName)的采样率; samplingServerURL如jaeger-agent的"http://127.0.0.1:5778/sampling"; 拉取到策略前按initialSamplingRate概率采样; refreshInterval及maxOperations<=0时使用jaeger的默认值
+func WithRemoteSampler(
+	samplingServerURL string, initialSamplingRate float64,
+	refreshInterval time.Duration, maxOperations int,
+) Option {
+	return WithSamplerConfig(&jaegerCfg.SamplerConfig{
+		Type:                    jaeger.SamplerTypeRemote,
+		Param:                   initialSamplingRate,
+		SamplingServerURL:       samplingServerURL,
+		SamplingRefreshInterval: refreshInterval,
+		MaxOperations:           maxOperations,
+	})
+}
@@ -0,0 +1,204 @@
+package tracer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/uber/jaeger-client-go"
+)
+
+func TestSamplerOptions(t *testing.T) {
+	tests := []struct {
+		name             string
+		opt              Option
+		opName           string
+		wantSampledCount int
+	}{
+		{
+			name:             "const sampler on",
+			opt:              WithConstSampler(true),
+			opName:           "op",
+			wantSampledCount: 5,
+		},
+		{
+			name:             "const sampler off",
+			opt:              WithConstSampler(false),
+			opName:           "op",
+			wantSampledCount: 0,
+		},
+		{
+			name:             "probabilistic sampler 0",
+			opt:              WithProbabilisticSampler(0),
+			opName:           "op",
+			wantSampledCount: 0,
+		},
+		{
+			name:             "probabilistic sampler 1",
+			opt:              WithProbabilisticSampler(1),
+			opName:           "op",
+			wantSampledCount: 5,
+		},
+		{
+			name:             "rate limiting sampler",
+			opt:              WithRateLimitingSampler(1),
+			opName:           "op",
+			wantSampledCount: 1,
+		},
+		{
+			name: "per operation sampler, configured operation",
+			opt: WithPerOperationSampler(
+				0, 0, map[string]float64{"HTTP GET /ping": 1}, 0,
+			),
+			opName:           "HTTP GET /ping",
+			wantSampledCount: 5,
+		},
+		{
+			// 未配置的操作按默认概率0采样; lowerBound为0时jaeger的限速采样器仍有1个初始额度, 所以只有第一个trace被采样
+			name: "per operation sampler, default rate",
+			opt: WithPerOperationSampler(
+				0, 0, map[string]float64{"HTTP GET /ping": 1}, 0,
+			),
+			opName:           "HTTP GET /pong",
+			wantSampledCount: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ti, _ = newTestTracer(t, tt.opt)
+			var sampledCount int
+			for i := 0; i < 5; i++ {
+				var span = ti.StartSpan(tt.opName)
+				if span.Context().(jaeger.SpanContext).IsSampled() {
+					sampledCount++
+				}
+				span.Finish()
+			}
+			if sampledCount != tt.wantSampledCount {
+				t.Errorf("sampled %v of 5 spans, want %v", sampledCount, tt.wantSampledCount)
+			}
+		})
+	}
+}
+
+func TestHttpMiddleWareSamplingDecision(t *testing.T) {
+	tests := []struct {
+		name         string
+		opt          Option
+		parentHeader string
+		wantSampled  bool
+		wantReported int
+	}{
+		{
+			name:         "unsampled root still propagates",
+			opt:          WithConstSampler(false),
+			wantSampled:  false,
+			wantReported: 0,
+		},
+		{
+			name:         "sampled parent overrides local sampler",
+			opt:          WithProbabilisticSampler(0),
+			parentHeader: "4d2:4d2:0:1",
+			wantSampled:  true,
+			wantReported: 1,
+		},
+		{
+			name:         "unsampled parent overrides local sampler",
+			opt:          WithConstSampler(true),
+			parentHeader: "4d2:4d2:0:0",
+			wantSampled:  false,
+			wantReported: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ti, reporter = newTestTracer(t, tt.opt)
+			var downstream = http.Header{}
+			var handler = ti.HttpMiddleWare(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					if err := ti.Inject2HttpHeaderByCtx(r.Context(), downstream); err != nil {
+						t.Errorf("Inject2HttpHeaderByCtx() error = %v", err)
+					}
+				},
+			))
+			var req = httptest.NewRequest(http.MethodGet, "/ping", nil)
+			if tt.parentHeader != "" {
+				req.Header.Set(OtMwTraceContextHeaderName, tt.parentHeader)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			var spanCtx, err = jaeger.ContextFromString(
+				downstream.Get(OtMwTraceContextHeaderName),
+			)
+			if err != nil {
+				t.Fatalf("downstream header not propagated: %v", err)
+			}
+			if spanCtx.IsSampled() != tt.wantSampled {
+				t.Errorf("IsSampled() = %v, want %v", spanCtx.IsSampled(), tt.wantSampled)
+			}
+			if got := reporter.SpansSubmitted(); got != tt.wantReported {
+				t.Errorf("SpansSubmitted() = %v, want %v", got, tt.wantReported)
+			}
+		})
+	}
+}
+
+func TestRemoteSampler(t *testing.T) {
+
+	var strategy atomic.Value
+	strategy.Store(`{"strategyType":"PROBABILISTIC","probabilisticSampling":{"samplingRate":0}}`)
+	var gotService = make(chan string, 1)
+	var server = httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case gotService <- r.URL.Query().Get("service"):
+			default:
+			}
+			w.Write([]byte(strategy.Load().(string)))
+		},
+	))
+	defer server.Close()
+
+	var ti, _ = newTestTracer(
+		t, WithRemoteSampler(server.URL, 0, 10*time.Millisecond, 0),
+	)
+	if service := <-gotService; service != "tracer-test" {
+		t.Errorf("sampling request service = %v, want tracer-test", service)
+	}
+	var span = ti.StartSpan("HTTP GET /ping")
+	span.Finish()
+	if span.Context().(jaeger.SpanContext).IsSampled() {
+		t.Fatalf("span sampled before the strategy is updated")
+	}
+
+	// 运行中调高单个操作的采样率
+	strategy.Store(`{"strategyType":"PROBABILISTIC","operationSampling":{"defaultSamplingProbability":0,"defaultLowerBoundTracesPerSecond":0,"perOperationStrategies":[{"operation":"HTTP GET /ping","probabilisticSampling":{"samplingRate":1}}]}}`)
+	var deadline = time.Now().Add(5 * time.Second)
+	for {
+		span = ti.StartSpan("HTTP GET /ping")
+		span.Finish()
+		if span.Context().(jaeger.SpanContext).IsSampled() {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("per-operation strategy not applied within 5s")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPerOperationSamplerNotShared(t *testing.T) {
+
+	var opt = WithPerOperationSampler(0, 0, nil, 0)
+	for i := 0; i < 2; i++ {
+		var ti, _ = newTestTracer(t, opt)
+		// 每个tracer的采样器都有1个初始额度, 共享采样器时第二个tracer的span不会被采样
+		var span = ti.StartSpan("op")
+		if !span.Context().(jaeger.SpanContext).IsSampled() {
+			t.Errorf("tracer %v: first span not sampled, sampler shared between tracers", i)
+		}
+		span.Finish()
+	}
+}
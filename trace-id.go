@@ -0,0 +1,23 @@
+package tracer
+
+import (
+	"github.com/opentracing/opentracing-go"
+	"github.com/uber/jaeger-client-go"
+)
+
+// TraceIDHeaderName 回写traceID时常用的响应头名称, 配合 WithTraceIDResponseHeader 使用
+const TraceIDHeaderName = "X-Trace-Id"
+
+// WithTraceIDResponseHeader 设置 HttpMiddleWare 及 FasthttpMiddleWare 在每个响应中以名为headerName的响应头回写当前请求的traceID, 便于根据客户端提供的traceID在jaeger中查找该请求; headerName为空时不回写(默认); 未采样的trace同样回写, 但在jaeger中查找不到, 被 WithSkipRequests 跳过的请求不回写, example: WithTraceIDResponseHeader(TraceIDHeaderName)
+func WithTraceIDResponseHeader(headerName string) Option {
+	return func(opts *options) { opts.traceIDHeaderName = headerName }
+}
+
+// traceIDFromSpan 获取span所在trace的traceID, span不是jaeger的span时返回空字符串
+func traceIDFromSpan(span opentracing.Span) (traceID string) {
+
+	if spanCtx, ok := span.Context().(jaeger.SpanContext); ok {
+		traceID = spanCtx.TraceID().String()
+	}
+	return
+}
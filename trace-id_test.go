@@ -0,0 +1,73 @@
+package tracer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/uber/jaeger-client-go"
+	"github.com/valyala/fasthttp"
+)
+
+func TestTraceIDResponseHeader(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []Option
+		fasthttp   bool
+		headerName string
+	}{
+		{name: "http disabled"},
+		{name: "http", opts: []Option{WithTraceIDResponseHeader(TraceIDHeaderName)}, headerName: TraceIDHeaderName},
+		{
+			name:       "http unsampled",
+			opts:       []Option{WithConstSampler(false), WithTraceIDResponseHeader("Trace-Id")},
+			headerName: "Trace-Id",
+		},
+		{name: "fasthttp disabled", fasthttp: true},
+		{
+			name:       "fasthttp",
+			opts:       []Option{WithTraceIDResponseHeader(TraceIDHeaderName)},
+			fasthttp:   true,
+			headerName: TraceIDHeaderName,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ti, _ = newTestTracer(t, append(tt.opts, WithGen128Bit(true))...)
+			var want, got string
+			if tt.fasthttp {
+				var ctx fasthttp.RequestCtx
+				ti.FasthttpMiddleWare(func(ctx *fasthttp.RequestCtx) {
+					want = traceIDFromSpan(ti.SpanFromContext(ctx))
+					// handler重置响应后仍需回写
+					ctx.Response.Reset()
+				})(&ctx)
+				if tt.headerName != "" {
+					got = string(ctx.Response.Header.Peek(tt.headerName))
+				} else if len(ctx.Response.Header.Peek(TraceIDHeaderName)) != 0 {
+					t.Errorf("trace id header written while disabled")
+				}
+			} else {
+				var w = httptest.NewRecorder()
+				ti.HttpMiddleWare(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					want = traceIDFromSpan(ti.SpanFromContext(r.Context()))
+					w.WriteHeader(http.StatusNoContent)
+				})).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+				if tt.headerName != "" {
+					got = w.Header().Get(tt.headerName)
+				} else if len(w.Header()) != 0 {
+					t.Errorf("Header() = %v, want empty", w.Header())
+				}
+			}
+			if tt.headerName == "" {
+				return
+			}
+			if got == "" || got != want {
+				t.Errorf("trace id header = %q, want %q", got, want)
+			}
+			if _, err := jaeger.TraceIDFromString(got); err != nil {
+				t.Errorf("TraceIDFromString(%q) error = %v", got, err)
+			}
+		})
+	}
+}
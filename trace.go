@@ -5,8 +5,8 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
-	"github.com/astaxie/beego/logs"
 	"github.com/opentracing/opentracing-go"
 	opentracingLog "github.com/opentracing/opentracing-go/log"
 	"github.com/uber/jaeger-client-go"
@@ -24,7 +24,11 @@ const otMwTraceBaggageHeaderPrefix = "ot-mw-ctx-"
 
 const httpMiddleWareComponentName = "ot-mw-tracer"
 
-const logFieldKeyHttpStatusCode = "http.status_code"
+// FasthttpUserValueKeySpan FasthttpMiddleWare 生成的span在 fasthttp.RequestCtx 的UserValue中的key, 值为内部的包装类型, 需通过 SpanFromContext 获取; 可直接将 *fasthttp.RequestCtx 或由其派生的ctx传给 ChildSpanFromContext, Inject2FasthttpHeaderByCtx 等方法
+const FasthttpUserValueKeySpan = "ot-mw-span"
+
+// fasthttpSpanValue FasthttpMiddleWare 存入UserValue的span, 包装为未导出的类型, 防止ctx中其他同名string类型key的值被误当作span
+type fasthttpSpanValue struct{ span opentracing.Span }
 
 // codecFormat for inject and extract to/from carrier, refer to opentracing.HTTPHeaders
 type codecFormat int
@@ -40,10 +44,8 @@ var activeSpanKey = contextKey{}
 // check for fasthttpHeadersCodecFormat's carrier
 var _ opentracing.TextMapWriter = &fasthttp.RequestHeader{}
 var _ opentracing.TextMapReader = &fasthttpRespHeaderCarrier{}
-
-var logFieldHttpMiddleWareComponentName = opentracingLog.String(
-	"component", httpMiddleWareComponentName,
-)
+var _ opentracing.TextMapReader = &fasthttpReqHeaderCarrier{}
+var _ opentracing.TextMapWriter = &fasthttp.ResponseHeader{}
 
 var pJaegerHeaderConfig = &jaeger.HeadersConfig{
 	JaegerDebugHeader:        otMwJaegerDebugHeader,
@@ -52,12 +54,13 @@ var pJaegerHeaderConfig = &jaeger.HeadersConfig{
 	TraceBaggageHeaderPrefix: otMwTraceBaggageHeaderPrefix,
 }
 var pJaegerNullMetrics = jaeger.NewNullMetrics()
-var pJaegerHttpHeaderPropagator = jaeger.NewHTTPHeaderPropagator(pJaegerHeaderConfig, *pJaegerNullMetrics)
-var pJaegerFasthttpHeaderPropagator = jaeger.NewHTTPHeaderPropagator(pJaegerHeaderConfig, *pJaegerNullMetrics)
 
 var noopTracerImpl = &tracerImpl{
-	tracer: defaultNoopTracer,
-	closer: defaultNoopCloser,
+	tracer:            defaultNoopTracer,
+	closer:            defaultNoopCloser,
+	operationNameFunc: OperationNameMethodPath,
+	fasthttpClient:    defaultFasthttpClient,
+	fasthttpTimeout:   httpClientTimeOut,
 }
 
 type Tracer interface {
@@ -97,12 +100,22 @@ type Tracer interface {
 	FollowerSpanFromFasthttpHeader(
 		opName string, header *fasthttp.ResponseHeader,
 	) (follower opentracing.Span)
+	// ChildSpanFromFasthttpRequestHeader 根据 fasthttp.RequestHeader 头里的span信息生成一个操作名称为opName的子span, 用于fasthttp服务端延续调用方的trace, 如果 fasthttp.RequestHeader 头没有span信息, 将生成一个操作名称为opName的起始span(父span)
+	ChildSpanFromFasthttpRequestHeader(
+		opName string, header *fasthttp.RequestHeader,
+	) (child opentracing.Span)
+	// FollowerSpanFromFasthttpRequestHeader 根据 fasthttp.RequestHeader 头里的span信息生成一个操作名称为opName的跟随span, 如果 fasthttp.RequestHeader 头没有span信息, 将生成一个操作名称为opName的起始span(父span)
+	FollowerSpanFromFasthttpRequestHeader(
+		opName string, header *fasthttp.RequestHeader,
+	) (follower opentracing.Span)
 	// LogCodeAndMsgToSpan 已log的形式记录code和msg到span
 	LogCodeAndMsgToSpan(span opentracing.Span, code int, msg string)
 	// ContextWithSpan 将span注入ctx生成新的ctx, ctxWithChild携带新生成的span信息, 当span为nil时返回传入的ctx
 	ContextWithSpan(ctx context.Context, span opentracing.Span) (
 		ctxWithSpan context.Context,
 	)
+	// SpanFromContext 获取ctx里通过 ContextWithSpan 或中间件(HttpMiddleWare, FasthttpMiddleWare)存入的span, 便于修改操作名称或添加tag, ctx里没有span时返回nil
+	SpanFromContext(ctx context.Context) (span opentracing.Span)
 	// CtxWithSpanCtxFromHttpHeader 从 http.Header 中获取 SpanContext 信息, 并将之注入到ctx中, 生成新的ctx, 当未获取到 SpanContext 信息时返回传入的ctx
 	CtxWithSpanCtxFromHttpHeader(ctx context.Context, header http.Header) (
 		newCtx context.Context,
@@ -111,6 +124,10 @@ type Tracer interface {
 	CtxWithSpanCtxFromFasthttpHeader(
 		ctx context.Context, header *fasthttp.ResponseHeader,
 	) (newCtx context.Context)
+	// CtxWithSpanCtxFromFasthttpRequestHeader 从 *fasthttp.RequestHeader 中获取 SpanContext 信息, 并将之注入到ctx中, 生成新的ctx, 当未获取到 SpanContext 信息时返回传入的ctx
+	CtxWithSpanCtxFromFasthttpRequestHeader(
+		ctx context.Context, header *fasthttp.RequestHeader,
+	) (newCtx context.Context)
 	// Inject2HttpHeader 将span信息打进http头里, 便于在不同服务间传递span信息
 	Inject2HttpHeader(span opentracing.Span, header http.Header) (err error)
 	// Inject2FasthttpHeader 将span信息打进fasthttp头里, 便于在不同服务间传递span信息
@@ -123,9 +140,25 @@ type Tracer interface {
 	Inject2FasthttpHeaderByCtx(
 		ctx context.Context, header *fasthttp.RequestHeader,
 	) (err error)
-	// HttpMiddleWare 返回带有该tracer信息的http.Handler, 返回的http.Handler将根据http的request的header里的span信息生成一个子span, 并将其注入的request.context中(如果http的request的header中没有span信息, 将生成一个父span, 并将其信息注入request.context中)
+	// Inject2FasthttpResponseHeader 将span信息打进fasthttp的响应头里, 便于fasthttp服务端将span信息返回给调用方
+	Inject2FasthttpResponseHeader(
+		span opentracing.Span, header *fasthttp.ResponseHeader,
+	) (err error)
+	// Inject2FasthttpResponseHeaderByCtx 将ctx里的span信息打进fasthttp的响应头里, ctx可以是经过 FasthttpMiddleWare 处理的 *fasthttp.RequestCtx
+	Inject2FasthttpResponseHeaderByCtx(
+		ctx context.Context, header *fasthttp.ResponseHeader,
+	) (err error)
+	// HttpMiddleWare 返回带有该tracer信息的http.Handler, 返回的http.Handler将根据http的request的header里的span信息生成一个子span, 并将其注入的request.context中(如果http的request的header中没有span信息, 将生成一个父span, 并将其信息注入request.context中); span的操作名称默认为 "HTTP 请求方法 请求路径", 可通过 WithOperationNameFunc 修改; handler发生panic时, span会记录error=true及panic的值和堆栈后结束, 之后的处理见 WithRecoverPanic; 可通过 WithSkipRequests 跳过健康检查等请求, 通过 WithForceSampleRequests 及 WithNeverSampleRequests 调整指定请求的采样决策, 通过 WithTraceIDResponseHeader 在响应头中回写traceID
 	HttpMiddleWare(handler http.Handler) (traceHandler http.Handler)
-	// GetFasthttp 通过fasthttp发起get请求
+	// FasthttpMiddleWare 返回带有该tracer信息的 fasthttp.RequestHandler, 根据请求头里的span信息生成一个子span(没有span信息时生成一个父span), 并将其存入 fasthttp.RequestCtx 的UserValue(key为 FasthttpUserValueKeySpan)中, 处理完成后记录状态码并结束span; handler发生panic时的处理同 HttpMiddleWare; 通过 WithTraceIDResponseHeader 在响应头中回写traceID
+	FasthttpMiddleWare(handler fasthttp.RequestHandler) (
+		traceHandler fasthttp.RequestHandler,
+	)
+	// HttpRoundTripper 返回带有该tracer信息的 http.RoundTripper, 用于 http.Client 的Transport; 每个请求根据request.context中的span信息生成一个子span(没有span信息时生成一个父span), 并将其信息打进请求头里, 记录请求方法, url, 状态码, 以及DNS解析, 建立连接, TLS握手, 收到首字节等耗时, 请求失败或状态码为5xx时设置 error=true; span在响应体读取完毕或关闭时结束; base为nil时使用 http.DefaultTransport, example: client := &http.Client{Transport: tracer.HttpRoundTripper(nil)}
+	HttpRoundTripper(base http.RoundTripper) (traced http.RoundTripper)
+	// Request 返回通过链式调用构造fasthttp请求的 RequestBuilder, 支持任意请求方法, 多值的请求头及查询参数, 原始字节, json, form及multipart格式的请求体, 发送时与 GetFasthttp 等方法一样创建client端的span并传递span信息
+	Request(ctx context.Context) (builder *RequestBuilder)
+	// GetFasthttp 通过fasthttp发起get请求; 超时时间取ctx的deadline与 FasthttpClientConfig.Timeout 中较小的值, ctx被取消或超时时立即返回ctx.Err(), PostJsonFasthttp 等方法同理
 	GetFasthttp(
 		ctx context.Context, url string,
 		mapHeader, mapCookie map[string]string, cbs ...FasthttpRespCallback,
@@ -148,35 +181,80 @@ type Tracer interface {
 }
 
 type tracerImpl struct {
-	tracer opentracing.Tracer
-	closer io.Closer
+	tracer            opentracing.Tracer
+	closer            io.Closer
+	operationNameFunc OperationNameFunc
+	recoverPanic      bool
+	capture           captureOptions
+	traceIDHeaderName string
+	fasthttpClient    *fasthttp.Client
+	fasthttpTimeout   time.Duration
+
+	skipRequestMatchers        []RequestMatcher
+	forceSampleRequestMatchers []RequestMatcher
+	neverSampleRequestMatchers []RequestMatcher
 }
 
 func InitEmptyTracer() Tracer { return noopTracerImpl }
 
-// NewTracerBySrvNameAndTracerSrvHost 根据服务名称和tracer服务地址创建Tracer实例, 目前内部的实现方式为全追踪模式并通过http直连jaeger服务端上报追踪信息且设置为opentracing中的全局唯一tracer, 内置的log为beego默认的BeeLogger; 返回的tracer可在服务内并发使用, 在程序退出前通过调用tracer.Close()释放tracer占用的资源; example: NewTracerBySrvNameAndTracerSrvHost("tracer-self", "http://127.0.0.1:14268")
+// NewTracer 根据服务名称及可选配置创建Tracer实例, 未指定的配置使用默认值: 全追踪模式, 通过udp上报到本机的jaeger-agent, 内部信息通过标准库log的默认logger输出, 使用ot-mw-*系列http头传递span信息; 返回的tracer可在服务内并发使用, 在程序退出前通过调用tracer.Close()释放tracer占用的资源; example: NewTracer("tracer-self", WithCollectorEndpoint("http://127.0.0.1:14268/api/traces"))
+func NewTracer(srvName string, opts ...Option) (tracer Tracer, err error) {
+
+	tracer, err = newTracerByOptions(srvName, newOptions(opts...))
+	return
+}
+
+// NewTracerBySrvNameAndTracerSrvHost 根据服务名称和tracer服务地址创建Tracer实例, 为全追踪模式并通过http直连jaeger服务端上报追踪信息, 内部信息通过标准库log的默认logger输出(此前为beego的BeeLogger, 使用beego的服务可改用 NewTracer 并设置 WithLogger(beegotracer.NewLogger(nil))), 其余配置同 NewTracer 的默认值; example: NewTracerBySrvNameAndTracerSrvHost("tracer-self", "http://127.0.0.1:14268")
 func NewTracerBySrvNameAndTracerSrvHost(srvName, tracerSrvHost string) (
 	tracer Tracer, err error,
 ) {
 
+	tracer, err = NewTracer(
+		srvName, WithCollectorEndpoint(tracerSrvHost+"/api/traces"),
+	)
+	return
+}
+
+func newTracerByOptions(srvName string, opts *options) (
+	tracer Tracer, err error,
+) {
+
+	var fasthttpClient *fasthttp.Client
+	var fasthttpTimeout time.Duration
+	if fasthttpClient, fasthttpTimeout, err = newFasthttpClient(
+		opts.fasthttpClientConfig,
+	); err != nil {
+		return
+	}
 	var opentracingTracer opentracing.Tracer
 	var closer io.Closer
-	if opentracingTracer, closer, err = newTracerInConstSampleWithBeegoLogByDR(
-		srvName, tracerSrvHost,
+	if opentracingTracer, closer, err = newJaegerTracer(
+		srvName, opts,
 	); err != nil {
 		return
 	}
 	// 取消设置为全局, 防止误用
 	// opentracing.SetGlobalTracer(opentracingTracer)
 	tracer = &tracerImpl{
-		tracer: opentracingTracer,
-		closer: closer,
+		tracer:            opentracingTracer,
+		closer:            closer,
+		operationNameFunc: opts.operationNameFunc,
+		recoverPanic:      opts.recoverPanic,
+		capture:           opts.capture,
+		traceIDHeaderName: opts.traceIDHeaderName,
+		fasthttpClient:    fasthttpClient,
+		fasthttpTimeout:   fasthttpTimeout,
+
+		skipRequestMatchers:        opts.skipRequestMatchers,
+		forceSampleRequestMatchers: opts.forceSampleRequestMatchers,
+		neverSampleRequestMatchers: opts.neverSampleRequestMatchers,
 	}
 	return
 }
 
 func (ti *tracerImpl) Close() (err error) {
 
+	ti.fasthttpClient.CloseIdleConnections()
 	err = ti.closer.Close()
 	return
 }
@@ -279,6 +357,26 @@ func (ti *tracerImpl) FollowerSpanFromFasthttpHeader(
 	return
 }
 
+func (ti *tracerImpl) ChildSpanFromFasthttpRequestHeader(
+	opName string, header *fasthttp.RequestHeader,
+) (child opentracing.Span) {
+
+	child = ti.getSpanFromFasthttpRequestHeader(
+		opName, header, opentracing.ChildOfRef,
+	)
+	return
+}
+
+func (ti *tracerImpl) FollowerSpanFromFasthttpRequestHeader(
+	opName string, header *fasthttp.RequestHeader,
+) (follower opentracing.Span) {
+
+	follower = ti.getSpanFromFasthttpRequestHeader(
+		opName, header, opentracing.FollowsFromRef,
+	)
+	return
+}
+
 func (ti *tracerImpl) LogCodeAndMsgToSpan(
 	span opentracing.Span, code int, msg string,
 ) {
@@ -305,6 +403,14 @@ func (ti *tracerImpl) ContextWithSpan(
 	return
 }
 
+func (ti *tracerImpl) SpanFromContext(ctx context.Context) (
+	span opentracing.Span,
+) {
+
+	span, _ = ti.spanInfoFromContext(ctx)
+	return
+}
+
 func (ti *tracerImpl) CtxWithSpanCtxFromHttpHeader(
 	ctx context.Context, header http.Header,
 ) (newCtx context.Context) {
@@ -337,6 +443,22 @@ func (ti *tracerImpl) CtxWithSpanCtxFromFasthttpHeader(
 	return
 }
 
+func (ti *tracerImpl) CtxWithSpanCtxFromFasthttpRequestHeader(
+	ctx context.Context, header *fasthttp.RequestHeader,
+) (newCtx context.Context) {
+
+	if ctx == nil {
+		return
+	}
+
+	if spanCtx, _ := ti.extractFromFasthttpRequestHeader(header); spanCtx == nil {
+		newCtx = ctx
+	} else {
+		newCtx = context.WithValue(ctx, activeSpanKey, spanCtx)
+	}
+	return
+}
+
 func (ti *tracerImpl) Inject2HttpHeader(
 	span opentracing.Span, header http.Header,
 ) (err error) {
@@ -381,6 +503,28 @@ func (ti *tracerImpl) Inject2FasthttpHeaderByCtx(
 	return
 }
 
+func (ti *tracerImpl) Inject2FasthttpResponseHeader(
+	span opentracing.Span, header *fasthttp.ResponseHeader,
+) (err error) {
+
+	if span != nil {
+		err = ti.injectSpanCtx2FasthttpResponseHeader(span.Context(), header)
+	}
+	return
+}
+
+func (ti *tracerImpl) Inject2FasthttpResponseHeaderByCtx(
+	ctx context.Context, header *fasthttp.ResponseHeader,
+) (err error) {
+
+	if span, spanCtx := ti.spanInfoFromContext(ctx); span != nil {
+		err = ti.injectSpanCtx2FasthttpResponseHeader(span.Context(), header)
+	} else if spanCtx != nil {
+		err = ti.injectSpanCtx2FasthttpResponseHeader(spanCtx, header)
+	}
+	return
+}
+
 func (ti *tracerImpl) HttpMiddleWare(handler http.Handler) (
 	traceHandler http.Handler,
 ) {
@@ -392,24 +536,122 @@ func (ti *tracerImpl) HttpMiddleWare(handler http.Handler) (
 
 	traceHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
-		var child = ti.ChildSpanFromHttpHeader(
-			getOperationNameFromHttpRequest(r), r.Header,
+		if matchRequest(ti.skipRequestMatchers, r) {
+			// 不生成span, 但保留上游的span信息, 使handler中的下游调用仍能继续传递
+			handler.ServeHTTP(w, r.WithContext(
+				ti.CtxWithSpanCtxFromHttpHeader(r.Context(), r.Header),
+			))
+			return
+		}
+
+		var child = ti.getSpanFromHttpHeader(
+			ti.operationNameFunc(r), r.Header, opentracing.ChildOfRef,
+			ti.samplingPriorityOptions(r)...,
 		)
+		setHttpServerSpanTags(child, r)
+		if ti.traceIDHeaderName != emptyString {
+			// 需在handler写入响应头之前设置
+			if traceID := traceIDFromSpan(child); traceID != emptyString {
+				w.Header().Set(ti.traceIDHeaderName, traceID)
+			}
+		}
 
 		r = r.WithContext(ti.ContextWithSpan(r.Context(), child))
-		var sct = &statusCodeTracker{ResponseWriter: w}
-		handler.ServeHTTP(sct, r)
+		var sct, tracked = newStatusCodeTracker(w)
+		var capture = ti.capture.shouldCapture(child)
+		var reqBody *captureBuffer
+		if capture {
+			if reqBody = ti.capture.newBodyBuffer(); reqBody != nil &&
+				r.Body != nil && r.Body != http.NoBody {
+				r.Body = &captureReadCloser{ReadCloser: r.Body, body: reqBody}
+			}
+			sct.body = ti.capture.newBodyBuffer()
+		}
+		defer func() {
+			if v := recover(); v != nil {
+				logPanicToSpan(child, v)
+				if ti.shouldRepanic(v) {
+					if capture {
+						ti.capture.logHttpExchange(
+							child, r.Header, reqBody, tracked.Header(), sct.body,
+						)
+					}
+					child.Finish()
+					panic(v)
+				}
+				if !sct.wroteHeader {
+					http.Error(
+						tracked,
+						http.StatusText(http.StatusInternalServerError),
+						http.StatusInternalServerError,
+					)
+				}
+			}
+			// handler未写入任何内容时, net/http同样以200响应
+			sct.writeImplicitHeader()
+			setHttpStatusCodeSpanTag(child, sct.statusCode)
+			child.SetTag(tagKeyHttpResponseSize, sct.bytesWritten)
+			if capture {
+				ti.capture.logHttpExchange(
+					child, r.Header, reqBody, tracked.Header(), sct.body,
+				)
+			}
+			child.Finish()
+		}()
+		handler.ServeHTTP(tracked, r)
+	})
+	return
+}
 
-		child.LogFields(
-			logFieldHttpMiddleWareComponentName,
-			opentracingLog.Int(logFieldKeyHttpStatusCode, sct.statusCode),
+func (ti *tracerImpl) FasthttpMiddleWare(handler fasthttp.RequestHandler) (
+	traceHandler fasthttp.RequestHandler,
+) {
+
+	if ti == noopTracerImpl {
+		traceHandler = handler
+		return
+	}
+
+	traceHandler = func(ctx *fasthttp.RequestCtx) {
+
+		var child = ti.ChildSpanFromFasthttpRequestHeader(
+			getOperationNameFromFasthttpRequest(ctx), &ctx.Request.Header,
 		)
-		child.Finish()
-	})
+		setFasthttpServerSpanTags(child, ctx)
+
+		ctx.SetUserValue(FasthttpUserValueKeySpan, fasthttpSpanValue{span: child})
+		defer func() {
+			if v := recover(); v != nil {
+				logPanicToSpan(child, v)
+				if ti.shouldRepanic(v) {
+					child.Finish()
+					panic(v)
+				}
+				ctx.Error(
+					fasthttp.StatusMessage(fasthttp.StatusInternalServerError),
+					fasthttp.StatusInternalServerError,
+				)
+			}
+			if ti.traceIDHeaderName != emptyString {
+				// fasthttp在handler返回后才写入响应头, 且handler中可能重置响应
+				if traceID := traceIDFromSpan(child); traceID != emptyString {
+					ctx.Response.Header.Set(ti.traceIDHeaderName, traceID)
+				}
+			}
+			setHttpStatusCodeSpanTag(child, ctx.Response.StatusCode())
+			if !ctx.Response.IsBodyStream() {
+				child.SetTag(
+					tagKeyHttpResponseSize, int64(len(ctx.Response.Body())),
+				)
+			}
+			child.Finish()
+		}()
+		handler(ctx)
+	}
 	return
 }
 
-// spanInfoFromContext 从ctx中获取span或spanCtx, 优先获取span, 当没有获取到span时再获取spanCtx, 当从ctx中都获取不到时返回(nil, nil)
+// spanInfoFromContext 从ctx中获取span或spanCtx, 优先获取span, 当没有获取到span时再获取spanCtx, 当从ctx中都获取不到时返回(nil, nil); ctx为 *fasthttp.RequestCtx 或由其派生时, 还会获取 FasthttpMiddleWare 存入UserValue的span
 func (ti *tracerImpl) spanInfoFromContext(ctx context.Context) (
 	span opentracing.Span, spanCtx opentracing.SpanContext,
 ) {
@@ -418,7 +660,14 @@ func (ti *tracerImpl) spanInfoFromContext(ctx context.Context) (
 		return
 	}
 
-	switch val := ctx.Value(activeSpanKey); v := val.(type) {
+	var val = ctx.Value(activeSpanKey)
+	if val == nil {
+		// fasthttp.RequestCtx 只支持string类型的key, 由其派生的ctx同样可以获取到
+		if v, ok := ctx.Value(FasthttpUserValueKeySpan).(fasthttpSpanValue); ok {
+			val = v.span
+		}
+	}
+	switch v := val.(type) {
 	case opentracing.Span:
 		span = v
 	case opentracing.SpanContext:
@@ -427,19 +676,21 @@ func (ti *tracerImpl) spanInfoFromContext(ctx context.Context) (
 	return
 }
 
-// getSpanFromHttpHeader 从 http.Header 中获取span, 并创建对应类型的子span, 当从 http.Header 中没获取到时将根据opName创建一个起始span(父span)
+// getSpanFromHttpHeader 从 http.Header 中获取span, 并创建对应类型的子span, 当从 http.Header 中没获取到时将根据opName创建一个起始span(父span); opts为创建span时的其他选项
 func (ti *tracerImpl) getSpanFromHttpHeader(
 	opName string, header http.Header, refType opentracing.SpanReferenceType,
+	opts ...opentracing.StartSpanOption,
 ) (span opentracing.Span) {
 
 	if spanCtx, _ := ti.extractFromHttpHeader(header); spanCtx == nil {
-		span = ti.tracer.StartSpan(opName)
+		span = ti.tracer.StartSpan(opName, opts...)
 	} else {
 		if refType == opentracing.ChildOfRef {
-			span = ti.tracer.StartSpan(opName, opentracing.ChildOf(spanCtx))
+			opts = append(opts, opentracing.ChildOf(spanCtx))
 		} else {
-			span = ti.tracer.StartSpan(opName, opentracing.FollowsFrom(spanCtx))
+			opts = append(opts, opentracing.FollowsFrom(spanCtx))
 		}
+		span = ti.tracer.StartSpan(opName, opts...)
 	}
 	return
 }
@@ -484,6 +735,35 @@ func (ti *tracerImpl) extractFromFasthttpHeader(
 	return
 }
 
+// getSpanFromFasthttpRequestHeader 从 fasthttp.RequestHeader 中获取span, 并创建对应类型的子span, 当从 fasthttp.RequestHeader 中没获取到时将根据opName创建一个起始span(父span)
+func (ti *tracerImpl) getSpanFromFasthttpRequestHeader(
+	opName string, header *fasthttp.RequestHeader,
+	refType opentracing.SpanReferenceType,
+) (span opentracing.Span) {
+
+	if spanCtx, _ := ti.extractFromFasthttpRequestHeader(header); spanCtx == nil {
+		span = ti.tracer.StartSpan(opName)
+	} else {
+		if refType == opentracing.ChildOfRef {
+			span = ti.tracer.StartSpan(opName, opentracing.ChildOf(spanCtx))
+		} else {
+			span = ti.tracer.StartSpan(opName, opentracing.FollowsFrom(spanCtx))
+		}
+	}
+	return
+}
+
+func (ti *tracerImpl) extractFromFasthttpRequestHeader(
+	header *fasthttp.RequestHeader,
+) (spanCtx opentracing.SpanContext, err error) {
+
+	spanCtx, err = ti.tracer.Extract(
+		fasthttpHeadersCodecFormat,
+		(*fasthttpReqHeaderCarrier)(header),
+	)
+	return
+}
+
 func (ti *tracerImpl) injectSpanCtx2HttpHeader(
 	spanCtx opentracing.SpanContext, header http.Header,
 ) (err error) {
@@ -503,6 +783,14 @@ func (ti *tracerImpl) injectSpanCtx2FasthttpHeader(
 	return
 }
 
+func (ti *tracerImpl) injectSpanCtx2FasthttpResponseHeader(
+	spanCtx opentracing.SpanContext, header *fasthttp.ResponseHeader,
+) (err error) {
+
+	err = ti.tracer.Inject(spanCtx, fasthttpHeadersCodecFormat, header)
+	return
+}
+
 func getOperationNameFromHttpRequest(r *http.Request) (opName string) {
 
 	var opNameBuild strings.Builder
@@ -516,63 +804,73 @@ func getOperationNameFromHttpRequest(r *http.Request) (opName string) {
 	return
 }
 
-func newTracerInConstSampleWithBeegoLogByDR(srvName, tracerSrvHost string) (
-	tracer opentracing.Tracer, closer io.Closer, err error,
+func getOperationNameFromFasthttpRequest(ctx *fasthttp.RequestCtx) (
+	opName string,
 ) {
 
-	tracer, closer, err = jaegerCfg.Configuration{
-		ServiceName: srvName,
-		// Constant (sampler.type=const) sampler always makes the same decision for all traces. It either samples all traces (sampler.param=1) or none of them (sampler.param=0).
-		Sampler: &jaegerCfg.SamplerConfig{
-			Type:  jaeger.SamplerTypeConst,
-			Param: 1,
-		},
-		Reporter: &jaegerCfg.ReporterConfig{
-			// BufferFlushInterval: 1 * time.Second,
-			// LogSpans:            true,
-			CollectorEndpoint: tracerSrvHost + "/api/traces",
-		},
-	}.NewTracer(
-		jaegerCfg.Logger(newJaegerLogByBeegoLog()),
-		jaegerCfg.Injector(
-			opentracing.HTTPHeaders, pJaegerHttpHeaderPropagator,
-		),
-		jaegerCfg.Extractor(
-			opentracing.HTTPHeaders, pJaegerHttpHeaderPropagator,
-		),
-		jaegerCfg.Injector(
-			fasthttpHeadersCodecFormat, pJaegerFasthttpHeaderPropagator,
-		),
-		jaegerCfg.Extractor(
-			fasthttpHeadersCodecFormat, pJaegerFasthttpHeaderPropagator,
-		),
-	)
+	var method, path = ctx.Method(), ctx.Path()
+	var opNameBuild strings.Builder
+	// 6 == len("HTTP ") + " "
+	opNameBuild.Grow(6 + len(method) + len(path))
+	opNameBuild.WriteString("HTTP ")
+	opNameBuild.Write(method)
+	opNameBuild.WriteByte(' ')
+	opNameBuild.Write(path)
+	opName = opNameBuild.String()
 	return
 }
 
-type beegoLog2JaegerLog struct {
-	pBeegoLog *logs.BeeLogger
-}
-
-func newJaegerLogByBeegoLog() (jLog jaeger.Logger) {
-	return &beegoLog2JaegerLog{pBeegoLog: logs.GetBeeLogger()}
-}
-
-func (b2j *beegoLog2JaegerLog) Error(msg string) { b2j.pBeegoLog.Warn(msg) }
-
-func (b2j *beegoLog2JaegerLog) Infof(msg string, args ...interface{}) {
-	b2j.pBeegoLog.Info(msg, args...)
-}
+func newJaegerTracer(srvName string, opts *options) (
+	tracer opentracing.Tracer, closer io.Closer, err error,
+) {
 
-type statusCodeTracker struct {
-	http.ResponseWriter
-	statusCode int
-}
+	var cfgOpts = []jaegerCfg.Option{
+		jaegerCfg.Logger(opts.logger),
+		jaegerCfg.Gen128Bit(opts.gen128Bit),
+		jaegerCfg.PoolSpans(opts.poolSpans),
+		jaegerCfg.MaxTagValueLength(opts.maxTagValueLength),
+		jaegerCfg.Injector(opentracing.HTTPHeaders, opts.propagator),
+		jaegerCfg.Extractor(opentracing.HTTPHeaders, opts.propagator),
+		jaegerCfg.Injector(fasthttpHeadersCodecFormat, opts.propagator),
+		jaegerCfg.Extractor(fasthttpHeadersCodecFormat, opts.propagator),
+	}
+	if opts.newSampler != nil {
+		cfgOpts = append(cfgOpts, jaegerCfg.Sampler(opts.newSampler()))
+	}
+	var reporter = opts.reporter
+	if reporter == nil && opts.agentMaxPacketSize > 0 &&
+		opts.reporterConfig.CollectorEndpoint == emptyString {
+		if reporter, err = newAgentReporter(opts); err != nil {
+			return
+		}
+		defer func() {
+			if err != nil {
+				reporter.Close()
+			}
+		}()
+	}
+	if reporter != nil {
+		cfgOpts = append(cfgOpts, jaegerCfg.Reporter(reporter))
+	}
 
-func (w *statusCodeTracker) WriteHeader(statusCode int) {
+	var samplerConfig = opts.samplerConfig
+	if samplerConfig.Type == jaeger.SamplerTypeRemote || samplerConfig.Type == "" {
+		// 远程采样拉取策略失败等信息同样输出到设置的log中
+		var cfg = *samplerConfig
+		cfg.Options = append(
+			[]jaeger.SamplerOption{jaeger.SamplerOptions.Logger(opts.logger)},
+			cfg.Options...,
+		)
+		samplerConfig = &cfg
+	}
 
-	w.statusCode = statusCode
-	w.ResponseWriter.WriteHeader(statusCode)
+	tracer, closer, err = jaegerCfg.Configuration{
+		ServiceName: srvName,
+		Tags:        opts.tags,
+		Sampler:     samplerConfig,
+		Reporter:    opts.reporterConfig,
+	}.NewTracer(cfgOpts...)
+	return
 }
 
 type fasthttpRespHeaderCarrier fasthttp.ResponseHeader
@@ -586,3 +884,18 @@ func (frhc *fasthttpRespHeaderCarrier) ForeachKey(
 	})
 	return
 }
+
+type fasthttpReqHeaderCarrier fasthttp.RequestHeader
+
+func (frhc *fasthttpReqHeaderCarrier) ForeachKey(
+	handler func(key, val string) error,
+) (err error) {
+
+	// 请求头的内存在请求处理完成后会被fasthttp复用, 而提取出的baggage, tracestate等可能随span存活更久, 所以需要复制
+	(*fasthttp.RequestHeader)(frhc).VisitAll(func(key, value []byte) {
+		if err == nil {
+			err = handler(string(key), string(value))
+		}
+	})
+	return
+}
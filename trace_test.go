@@ -0,0 +1,214 @@
+package tracer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/uber/jaeger-client-go"
+	"github.com/valyala/fasthttp"
+)
+
+// newTestTracer 创建一个将span上报到内存的tracer, 便于检查上报结果
+func newTestTracer(t *testing.T, opts ...Option) (
+	tracer Tracer, reporter *jaeger.InMemoryReporter,
+) {
+
+	reporter = jaeger.NewInMemoryReporter()
+	var err error
+	if tracer, err = NewTracer(
+		"tracer-test", append(
+			[]Option{WithReporter(reporter), WithLogger(NewNopLogger())},
+			opts...,
+		)...,
+	); err != nil {
+		t.Fatalf("NewTracer() error = %v", err)
+	}
+	t.Cleanup(func() { tracer.Close() })
+	return
+}
+
+func TestNewTracer(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		wantSampled bool
+		wantTags    map[string]interface{}
+	}{
+		{
+			name:        "default options",
+			wantSampled: true,
+		},
+		{
+			name:        "global tags",
+			opts:        []Option{WithTags(opentracing.Tag{Key: "env", Value: "test"})},
+			wantSampled: true,
+			wantTags:    map[string]interface{}{"env": "test"},
+		},
+		{
+			name:        "custom sampler",
+			opts:        []Option{WithSampler(jaeger.NewConstSampler(false))},
+			wantSampled: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ti, reporter = newTestTracer(t, tt.opts...)
+			var span = ti.StartSpan("test-op")
+			span.Finish()
+
+			var spanCtx = span.Context().(jaeger.SpanContext)
+			if spanCtx.IsSampled() != tt.wantSampled {
+				t.Errorf("IsSampled() = %v, want %v", spanCtx.IsSampled(), tt.wantSampled)
+			}
+			if !tt.wantSampled {
+				return
+			}
+			if got := reporter.SpansSubmitted(); got != 1 {
+				t.Fatalf("SpansSubmitted() = %v, want 1", got)
+			}
+			var process = reporter.GetSpans()[0].(*jaeger.Span).Tracer().(*jaeger.Tracer).Tags()
+			for key, want := range tt.wantTags {
+				var found bool
+				for _, tag := range process {
+					if tag.Key == key && tag.Value == want {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("process tag %s = %v not found in %v", key, want, process)
+				}
+			}
+		})
+	}
+}
+
+func TestFasthttpMiddleWare(t *testing.T) {
+	tests := []struct {
+		name       string
+		withParent bool
+		statusCode int
+	}{
+		{name: "root span", statusCode: fasthttp.StatusOK},
+		{name: "child of caller", withParent: true, statusCode: fasthttp.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ti, reporter = newTestTracer(t)
+			var parent = ti.StartSpan("caller")
+			defer parent.Finish()
+
+			var ctx fasthttp.RequestCtx
+			ctx.Request.Header.SetMethod(fasthttp.MethodGet)
+			ctx.Request.SetRequestURI("/users/1?name=a")
+			if tt.withParent {
+				if err := ti.Inject2FasthttpHeader(parent, &ctx.Request.Header); err != nil {
+					t.Fatalf("Inject2FasthttpHeader() error = %v", err)
+				}
+			}
+			var inHandler opentracing.Span
+			ti.FasthttpMiddleWare(func(ctx *fasthttp.RequestCtx) {
+				inHandler = ti.SpanFromContext(ctx)
+				var downstream fasthttp.RequestHeader
+				if err := ti.Inject2FasthttpHeaderByCtx(ctx, &downstream); err != nil {
+					t.Errorf("Inject2FasthttpHeaderByCtx() error = %v", err)
+				}
+				if len(downstream.Peek(OtMwTraceContextHeaderName)) == 0 {
+					t.Errorf("span in RequestCtx not injected")
+				}
+				if err := ti.Inject2FasthttpResponseHeaderByCtx(
+					ctx, &ctx.Response.Header,
+				); err != nil {
+					t.Errorf("Inject2FasthttpResponseHeaderByCtx() error = %v", err)
+				}
+				ctx.SetStatusCode(tt.statusCode)
+			})(&ctx)
+
+			if inHandler == nil {
+				t.Fatalf("span not stored in RequestCtx")
+			}
+			if len(ctx.Response.Header.Peek(OtMwTraceContextHeaderName)) == 0 {
+				t.Errorf("span not injected into response header")
+			}
+			if got := reporter.SpansSubmitted(); got != 1 {
+				t.Fatalf("SpansSubmitted() = %v, want 1", got)
+			}
+			var span = reporter.GetSpans()[0].(*jaeger.Span)
+			if span.OperationName() != "HTTP GET /users/1" {
+				t.Errorf("OperationName() = %v", span.OperationName())
+			}
+			var spanCtx = span.SpanContext()
+			var parentCtx = parent.Context().(jaeger.SpanContext)
+			if joined := spanCtx.ParentID() == parentCtx.SpanID(); joined != tt.withParent {
+				t.Errorf("joined = %v, want %v", joined, tt.withParent)
+			}
+			var tags = span.Tags()
+			if tags["http.method"] != fasthttp.MethodGet || tags["http.url"] != "/users/1?name=a" ||
+				tags["http.status_code"] != uint16(tt.statusCode) {
+				t.Errorf("Tags() = %v, want status code %v", tags, tt.statusCode)
+			}
+		})
+	}
+}
+
+func TestFasthttpServerPropagation(t *testing.T) {
+
+	var ti, _ = newTestTracer(t)
+	var caller = ti.StartSpan("caller")
+	defer caller.Finish()
+	var req fasthttp.RequestHeader
+	if err := ti.Inject2FasthttpHeader(caller, &req); err != nil {
+		t.Fatalf("Inject2FasthttpHeader() error = %v", err)
+	}
+	var want = caller.Context().(jaeger.SpanContext)
+
+	var server = ti.ChildSpanFromFasthttpRequestHeader("server", &req)
+	defer server.Finish()
+	if got := server.Context().(jaeger.SpanContext); got.TraceID() != want.TraceID() ||
+		got.ParentID() != want.SpanID() {
+		t.Errorf("ChildSpanFromFasthttpRequestHeader() = %v, want child of %v", got, want)
+	}
+	var follower = ti.ChildSpanFromContext(
+		"follower", ti.CtxWithSpanCtxFromFasthttpRequestHeader(context.Background(), &req),
+	)
+	defer follower.Finish()
+	if got := follower.Context().(jaeger.SpanContext); got.ParentID() != want.SpanID() {
+		t.Errorf("CtxWithSpanCtxFromFasthttpRequestHeader() = %v, want child of %v", got, want)
+	}
+
+	var resp fasthttp.ResponseHeader
+	if err := ti.Inject2FasthttpResponseHeader(server, &resp); err != nil {
+		t.Fatalf("Inject2FasthttpResponseHeader() error = %v", err)
+	}
+	var back = ti.ChildSpanFromFasthttpHeader("back", &resp)
+	defer back.Finish()
+	if got := back.Context().(jaeger.SpanContext); got.ParentID() !=
+		server.Context().(jaeger.SpanContext).SpanID() {
+		t.Errorf("Inject2FasthttpResponseHeader() = %v, want child of server", got)
+	}
+}
+
+func TestSpanFromContextFasthttpUserValue(t *testing.T) {
+
+	var ti, _ = newTestTracer(t)
+	var span = ti.StartSpan("op")
+	defer span.Finish()
+	var ctx = context.WithValue(context.Background(), FasthttpUserValueKeySpan, span)
+	if got := ti.SpanFromContext(ctx); got != nil {
+		t.Errorf("SpanFromContext() = %v, want nil for plain string key", got)
+	}
+
+	var inHandler, inDerived opentracing.Span
+	var requestCtx fasthttp.RequestCtx
+	requestCtx.Request.SetRequestURI("/users/1")
+	ti.FasthttpMiddleWare(func(ctx *fasthttp.RequestCtx) {
+		inHandler = ti.SpanFromContext(ctx)
+		// 未经 fasthttp.Server 处理的RequestCtx不支持Done, 这里只通过WithValue派生
+		inDerived = ti.SpanFromContext(context.WithValue(ctx, derivedCtxKey{}, "v"))
+	})(&requestCtx)
+	if inHandler == nil || inDerived != inHandler {
+		t.Errorf("SpanFromContext() = %v in handler, %v in derived ctx", inHandler, inDerived)
+	}
+}
+
+type derivedCtxKey struct{}